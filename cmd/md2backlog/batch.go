@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"md2backlog/internal/converter"
+	"md2backlog/internal/include"
+)
+
+// defaultOutputSuffix はMarkdownファイルに対応する変換結果のファイルの既定の拡張子です
+const defaultOutputSuffix = ".backlog"
+
+// markdownExtensions は一括変換の対象とするMarkdownファイルの拡張子です
+var markdownExtensions = []string{".md", ".markdown"}
+
+// isMarkdownFile は一括変換の対象とするMarkdownファイルかどうかを判定します
+func isMarkdownFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, markdownExt := range markdownExtensions {
+		if ext == markdownExt {
+			return true
+		}
+	}
+	return false
+}
+
+// outputPathFor はMarkdownファイルに対応する変換結果のファイルのパスを返します（docs/a.md → docs/a.backlog）
+func outputPathFor(input, suffix string) string {
+	return strings.TrimSuffix(input, filepath.Ext(input)) + suffix
+}
+
+// expandOutputTemplate は --output-template の {{dir}}・{{name}}・{{ext}} を入力ファイルのパスで置き換えます
+// （docs/a.md の場合は docs・a・.md）
+func expandOutputTemplate(template, input string) (string, error) {
+	ext := filepath.Ext(input)
+	output := strings.NewReplacer(
+		"{{dir}}", filepath.Dir(input),
+		"{{name}}", strings.TrimSuffix(filepath.Base(input), ext),
+		"{{ext}}", ext,
+	).Replace(template)
+	if i := strings.Index(output, "{{"); i >= 0 && strings.Contains(output[i:], "}}") {
+		return "", fmt.Errorf("unknown placeholder in output template %q (use {{dir}}, {{name}} or {{ext}})", template)
+	}
+	return filepath.Clean(output), nil
+}
+
+// convertFile はファイルの内容を変換します
+// path は相対リンクの解決に使うパスで、内容はinputから読み取ります（Gitのステージされた内容などを変換するため）
+// shared はsharedConverterOptionsで組み立てた共通の変換オプションで、文書ごとのオプションを加えて使います
+func convertFile(path string, input []byte, shared []converter.Option) (*converter.Result, error) {
+	opts := append(slices.Clip(shared), documentOptions(path)...)
+	return convertDocument(path, input, opts)
+}
+
+// convertDocument は組み立て済みの変換オプションでファイルの内容を変換します
+// フロントマターは本文から除いて変換し、警告や見出しなどの行番号は入力ファイルの行に合わせます
+// --includes の場合は変換の前にinclude指示を展開します
+func convertDocument(path string, input []byte, opts []converter.Option) (*converter.Result, error) {
+	frontMatter, markdown := converter.ExtractFrontMatter(string(input))
+	if resolveIncludes {
+		resolved, err := include.Resolve(path, []byte(markdown))
+		if err != nil {
+			return nil, err
+		}
+		markdown = string(resolved)
+	}
+
+	result, err := converter.ConvertDetailed(markdown, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if frontMatter != "" {
+		// --- の2行とフロントマターの行
+		shiftLines(result, strings.Count(frontMatter, "\n")+2)
+	}
+	return result, nil
+}
+
+// shiftLines は変換結果の行番号をずらします（行番号が不明（0）の場合はずらさない）
+func shiftLines(result *converter.Result, offset int) {
+	shift := func(line *int) {
+		if *line != 0 {
+			*line += offset
+		}
+	}
+	for i := range result.Warnings {
+		shift(&result.Warnings[i].Line)
+	}
+	for i := range result.Metadata.Headings {
+		shift(&result.Metadata.Headings[i].Line)
+	}
+	for i := range result.Metadata.Links {
+		shift(&result.Metadata.Links[i].Line)
+	}
+	for i := range result.Metadata.Images {
+		shift(&result.Metadata.Images[i].Line)
+	}
+}
+
+// isUpToDate は変換結果のファイルが最新かどうかを判定します（ファイルがない場合は最新ではありません）
+func isUpToDate(path, body string) (bool, error) {
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(current, []byte(body)), nil
+}
+
+// writeFileAtomic は一時ファイルに書き込んでから置き換えることで、書き込みの途中の内容を残さずにファイルを更新します
+// 既存のファイルのパーミッションは保ち、新しいファイルは0644で作成します
+func writeFileAtomic(path string, data []byte) (err error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// renameで置き換えられるよう、一時ファイルは同じディレクトリに作る
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"md2backlog/internal/converter"
+)
+
+var (
+	diagramCommands map[string]string
+	diagramCaption  bool
+	diagramDir      string
+)
+
+// diagramTempDir は--diagram-dirを省略してアップロードする場合に画像を生成する一時ディレクトリです
+var diagramTempDir string
+
+// captionAttribute は情報文字列の caption="..." / title="..." 属性にマッチします
+var captionAttribute = regexp.MustCompile(`\b(?:caption|title)="([^"]*)"`)
+
+// diagramHook はコードブロックを外部コマンドで画像に変換するフックです
+type diagramHook struct {
+	// commands は言語ごとのコマンドです（{input}と{output}はファイルのパスに置き換えます）
+	commands map[string]string
+	// caption は説明を表示するかどうかです
+	caption bool
+	// dir は画像を生成するディレクトリです
+	dir string
+	// absolute は画像のパスを絶対パスで返すかどうかです（アップロード時は入力ファイルの位置に依存させないため）
+	absolute bool
+}
+
+// HandleCodeBlock はコードブロックを画像に変換し、必要に応じて説明を付けます
+func (h *diagramHook) HandleCodeBlock(block converter.CodeBlock) (*converter.CodeBlockOutput, error) {
+	output := &converter.CodeBlockOutput{}
+	if h.caption {
+		output.Caption = blockCaption(block)
+	}
+
+	if command, ok := h.commands[strings.ToLower(block.Language)]; ok {
+		image, err := h.render(command, block)
+		if err != nil {
+			return nil, err
+		}
+		output.Image = image
+	}
+
+	if *output == (converter.CodeBlockOutput{}) {
+		return nil, nil
+	}
+	return output, nil
+}
+
+// render はコマンドを実行して画像を生成し、そのパスを返します
+// 同じ内容のブロックを同じコマンドで変換する場合は同じファイル名になるため、生成済みの画像は再利用します
+func (h *diagramHook) render(command string, block converter.CodeBlock) (string, error) {
+	dir := h.dir
+	if dir == "" {
+		var err error
+		if dir, err = ensureDiagramTempDir(); err != nil {
+			return "", err
+		}
+	}
+
+	// コマンド（参照する環境変数を展開したもの）を変えた場合は画像を作り直す
+	sum := sha256.Sum256([]byte(os.ExpandEnv(command) + "\x00" + block.Content))
+	name := strings.ToLower(block.Language) + "-" + hex.EncodeToString(sum[:])[:12]
+	input := filepath.Join(dir, name+".txt")
+	output := filepath.Join(dir, name+".png")
+	if _, err := os.Stat(output); err != nil {
+		if err := h.run(command, block.Content, dir, input, output); err != nil {
+			return "", err
+		}
+	}
+
+	if h.absolute {
+		return filepath.Abs(output)
+	}
+	return filepath.ToSlash(output), nil
+}
+
+// run は内容を入力ファイルに書き出し、コマンドを実行して画像を生成します
+func (h *diagramHook) run(command, content, dir, input, output string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(input)
+
+	replacer := strings.NewReplacer("{input}", shellQuote(input), "{output}", shellQuote(output))
+	cmd := exec.Command("sh", "-c", replacer.Replace(command))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	if _, err := os.Stat(output); err != nil {
+		return fmt.Errorf("command did not create %s", output)
+	}
+	return nil
+}
+
+// blockCaption は情報文字列の属性から説明を取り出します（属性がなければ言語名）
+func blockCaption(block converter.CodeBlock) string {
+	if m := captionAttribute.FindStringSubmatch(block.Info); m != nil {
+		return m[1]
+	}
+	return block.Language
+}
+
+// shellQuote はsh -cに渡すために文字列をシングルクォートで囲みます
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ensureDiagramTempDir は一時ディレクトリを作成して返します（作成済みであればそれを返します）
+func ensureDiagramTempDir() (string, error) {
+	if diagramTempDir != "" {
+		return diagramTempDir, nil
+	}
+	dir, err := os.MkdirTemp("", "md2backlog-diagrams")
+	if err != nil {
+		return "", err
+	}
+	diagramTempDir = dir
+	return dir, nil
+}
+
+// removeDiagramTempDir は一時ディレクトリを削除します
+func removeDiagramTempDir() {
+	if diagramTempDir != "" {
+		os.RemoveAll(diagramTempDir)
+		diagramTempDir = ""
+	}
+}
+
+// diagramOptions はフラグの値からコードブロックのフックを組み立てます
+func diagramOptions() ([]converter.Option, error) {
+	if len(diagramCommands) == 0 && !diagramCaption {
+		return nil, nil
+	}
+	if len(diagramCommands) > 0 && diagramDir == "" && !uploadAttachments {
+		return nil, &usageError{err: errors.New("--diagram-command requires --diagram-dir or --upload-attachments")}
+	}
+
+	hook := &diagramHook{
+		commands: map[string]string{},
+		caption:  diagramCaption,
+		dir:      diagramDir,
+		absolute: uploadAttachments,
+	}
+	languages := append([]string{}, converter.DiagramLanguages...)
+	for language, command := range diagramCommands {
+		language = strings.ToLower(language)
+		hook.commands[language] = command
+		languages = append(languages, language)
+	}
+	return []converter.Option{converter.WithCodeBlockHook(hook, languages...)}, nil
+}
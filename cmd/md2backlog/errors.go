@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"md2backlog/internal/converter"
+)
+
+// 終了コード
+const (
+	// exitFailure はその他のエラー（APIの呼び出しやGitの失敗など）です
+	exitFailure = 1
+	// exitUsage はフラグや引数の誤りです
+	exitUsage = 2
+	// exitInputNotFound は入力ファイルがない場合です
+	exitInputNotFound = 3
+	// exitReadError は入力を読み取れない場合です
+	exitReadError = 4
+	// exitConversionError は変換（テンプレートやinclude指示の展開を含む）に失敗した場合です
+	exitConversionError = 5
+	// exitWriteError は出力を書き込めない場合です
+	exitWriteError = 6
+	// exitStrictViolation は --strict で変換時に警告があった場合です
+	exitStrictViolation = 7
+)
+
+var (
+	quiet  bool
+	strict bool
+)
+
+// usageError はフラグや引数の誤りです
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// inputNotFoundError は入力ファイルがないことを表します
+type inputNotFoundError struct {
+	path string
+}
+
+func (e *inputNotFoundError) Error() string { return "input file not found: " + e.path }
+
+// readError は入力の読み取りの失敗です
+type readError struct {
+	path string
+	err  error
+}
+
+func (e *readError) Error() string { return fmt.Sprintf("reading %s: %v", e.path, e.err) }
+func (e *readError) Unwrap() error { return e.err }
+
+// conversionError は変換の失敗です
+type conversionError struct {
+	path string
+	err  error
+}
+
+func (e *conversionError) Error() string { return fmt.Sprintf("converting %s: %v", e.path, e.err) }
+func (e *conversionError) Unwrap() error { return e.err }
+
+// writeError は出力の書き込みの失敗です
+type writeError struct {
+	path string
+	err  error
+}
+
+func (e *writeError) Error() string { return fmt.Sprintf("writing %s: %v", e.path, e.err) }
+func (e *writeError) Unwrap() error { return e.err }
+
+// strictError は --strict で変換時に警告があったことを表します
+type strictError struct {
+	path     string
+	warnings []converter.Warning
+}
+
+func (e *strictError) Error() string {
+	return fmt.Sprintf("%s: %d warning(s) in strict mode", e.path, len(e.warnings))
+}
+
+// exitCode はエラーの種類に応じた終了コードを返します
+func exitCode(err error) int {
+	var (
+		usage      *usageError
+		notFound   *inputNotFoundError
+		read       *readError
+		conversion *conversionError
+		write      *writeError
+		strictErr  *strictError
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &notFound):
+		return exitInputNotFound
+	case errors.As(err, &read):
+		return exitReadError
+	case errors.As(err, &conversion):
+		return exitConversionError
+	case errors.As(err, &write):
+		return exitWriteError
+	case errors.As(err, &strictErr):
+		return exitStrictViolation
+	}
+	return exitFailure
+}
+
+// inputName はメッセージに使う入力の名前です（標準入力の場合は stdin）
+func inputName(path string) string {
+	if path == "" {
+		return "stdin"
+	}
+	return path
+}
+
+// readInput はファイル（pathが空の場合は標準入力）の内容を読み取ります
+func readInput(path string) ([]byte, error) {
+	var input []byte
+	var err error
+	if path == "" {
+		input, err = io.ReadAll(os.Stdin)
+	} else {
+		input, err = os.ReadFile(path)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &inputNotFoundError{path: path}
+	}
+	if err != nil {
+		return nil, &readError{path: inputName(path), err: err}
+	}
+	return input, nil
+}
+
+// writeOutput はファイルに出力を書き込みます
+func writeOutput(path string, data []byte) error {
+	if err := writeFileAtomic(path, data); err != nil {
+		return &writeError{path: path, err: err}
+	}
+	return nil
+}
+
+// checkStrict は --strict の場合に、変換時の警告をエラーにします
+func checkStrict(path string, warnings []converter.Warning) error {
+	if !strict || len(warnings) == 0 {
+		return nil
+	}
+	return &strictError{path: inputName(path), warnings: warnings}
+}
+
+// logWriter は警告や進捗のメッセージの出力先です（--quiet の場合は出力しません）
+func logWriter() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// printError はエラーを標準エラー出力に表示します
+func printError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
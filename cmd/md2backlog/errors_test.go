@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"md2backlog/internal/converter"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "no error", err: nil, expected: 0},
+		{name: "other", err: errors.New("boom"), expected: exitFailure},
+		{name: "usage", err: &usageError{err: errors.New("bad flag")}, expected: exitUsage},
+		{name: "input not found", err: &inputNotFoundError{path: "a.md"}, expected: exitInputNotFound},
+		{name: "read", err: &readError{path: "a.md", err: errors.New("denied")}, expected: exitReadError},
+		{name: "conversion", err: &conversionError{path: "a.md", err: errors.New("cycle")}, expected: exitConversionError},
+		{name: "write", err: &writeError{path: "a.txt", err: errors.New("full")}, expected: exitWriteError},
+		{name: "strict", err: &strictError{path: "a.md", warnings: []converter.Warning{{Message: "x"}}}, expected: exitStrictViolation},
+		{name: "wrapped", err: fmt.Errorf("context: %w", &writeError{path: "a.txt", err: errors.New("full")}), expected: exitWriteError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.err); code != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestExitCodeIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	warnPath := filepath.Join(dir, "warn.md")
+	// HTMLブロックはBacklog記法に変換できないため警告になる
+	if err := os.WriteFile(warnPath, []byte("<div>html</div>\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	outputPath := filepath.Join(dir, "out.txt")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "missing input", args: []string{"-i", filepath.Join(dir, "missing.md")}, expected: exitInputNotFound},
+		{name: "unknown flag", args: []string{"--no-such-flag"}, expected: exitUsage},
+		{name: "invalid flag value", args: []string{"-i", warnPath, "--toc", "sideways"}, expected: exitUsage},
+		{name: "unwritable output", args: []string{"-i", warnPath, "-o", filepath.Join(dir, "missing", "out.txt")}, expected: exitWriteError},
+		{name: "strict", args: []string{"-i", warnPath, "-o", outputPath, "--strict", "--quiet"}, expected: exitStrictViolation},
+		{name: "subcommand flag", args: []string{"split", "--no-such-flag"}, expected: exitUsage},
+		{name: "diagram command without output dir", args: []string{"-i", warnPath, "--diagram-command", "mermaid=mmdc -i {input} -o {output}"}, expected: exitUsage},
+		{name: "fetch users without api key", args: []string{"-i", warnPath, "--fetch-users", "PROJ"}, expected: exitUsage},
+		{name: "missing user map", args: []string{"-i", warnPath, "--user-map", filepath.Join(dir, "missing.json")}, expected: exitInputNotFound},
+		{name: "missing emoji map", args: []string{"-i", warnPath, "--emoji-map", filepath.Join(dir, "missing.json")}, expected: exitInputNotFound},
+		{name: "invalid emoji map", args: []string{"-i", warnPath, "--emoji-map", warnPath}, expected: exitReadError},
+		{name: "attachments with markdown format", args: []string{"-i", warnPath, "--format", "markdown", "--upload-attachments", "--backlog-url", "https://example.backlog.com", "--api-key", "secret", "--issue", "PROJ-1"}, expected: exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRootCmd()
+			rootCmd.SetArgs(tt.args)
+			err := rootCmd.Execute()
+			if code := exitCode(err); code != tt.expected {
+				t.Errorf("Expected exit code %d, got %d (%v)", tt.expected, code, err)
+			}
+		})
+	}
+
+	// --strict の場合は出力を書き込まない
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no output with --strict, got %v", err)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	defer func() { quiet = false }()
+
+	quiet = true
+	if logWriter() != io.Discard {
+		t.Error("Expected messages to be discarded with --quiet")
+	}
+	quiet = false
+	if logWriter() != os.Stderr {
+		t.Error("Expected messages on stderr")
+	}
+}
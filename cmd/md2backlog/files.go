@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// fileOutput は入力ファイルの変換結果とその書き込み先です
+type fileOutput struct {
+	path string
+	body string
+}
+
+// convertFiles は複数の入力ファイルを変換し、入力ファイルごとの出力先に書き込みます
+// 出力先は --in-place（入力ファイルの隣に --suffix の拡張子で）または --output-template で決めます
+// 途中の入力ファイルで変換に失敗した場合に一部の出力だけが更新されないよう、すべて変換してから書き込みます
+func convertFiles(cmd *cobra.Command, files []string) error {
+	if err := validateFileOutputFlags(cmd); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return &usageError{err: errors.New("--in-place and --output-template require input files")}
+	}
+
+	// 出力先を先に決め、入力ファイル自身や同じ出力先への書き込みを変換の前に検出する
+	outputs := make([]fileOutput, len(files))
+	seen := map[string]string{}
+	for i, file := range files {
+		path, err := outputPathForFile(file)
+		if err != nil {
+			return &usageError{err: err}
+		}
+		if filepath.Clean(path) == filepath.Clean(file) {
+			return &usageError{err: fmt.Errorf("%s: output path is the same as the input", file)}
+		}
+		if other, ok := seen[path]; ok {
+			return &usageError{err: fmt.Errorf("%s and %s are both written to %s", other, file, path)}
+		}
+		seen[path] = file
+		outputs[i].path = path
+	}
+
+	// 共通の変換オプションは1回だけ組み立て、入力ファイルごとに相対リンクの解決だけを加える
+	shared, err := sharedConverterOptions()
+	if err != nil {
+		return err
+	}
+	for i, file := range files {
+		opts := append(slices.Clip(shared), documentOptions(file)...)
+		input, err := readInput(file)
+		if err != nil {
+			return err
+		}
+		result, body, err := convertOutput(file, input, opts)
+		if err != nil {
+			return &conversionError{path: file, err: err}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(logWriter(), "Warning: %s: %s\n", file, warning)
+		}
+		if err := checkStrict(file, result.Warnings); err != nil {
+			return err
+		}
+		outputs[i].body = body
+	}
+
+	for _, output := range outputs {
+		if err := writeOutput(output.path, []byte(output.body)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFileOutputFlags は入力ファイルごとに出力する場合のフラグの組み合わせを検証します
+func validateFileOutputFlags(cmd *cobra.Command) error {
+	switch {
+	case !inPlace && outputTemplate == "":
+		return &usageError{err: errors.New("multiple input files require --in-place or --output-template")}
+	case inPlace && outputTemplate != "":
+		return &usageError{err: errors.New("--in-place and --output-template cannot be used together")}
+	case outputFile != "":
+		return &usageError{err: errors.New("--output cannot be used with --in-place, --output-template or multiple input files")}
+	case uploadAttachments:
+		return &usageError{err: errors.New("--upload-attachments cannot be used with --in-place, --output-template or multiple input files")}
+	case cmd.Flags().Changed("suffix") && !inPlace:
+		return &usageError{err: errors.New("--suffix requires --in-place")}
+	}
+	return nil
+}
+
+// outputPathForFile は入力ファイルの出力先を返します
+func outputPathForFile(file string) (string, error) {
+	if inPlace {
+		return outputPathFor(file, outputSuffix), nil
+	}
+	return expandOutputTemplate(outputTemplate, file)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestInPlaceIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.md":      "# A",
+		"docs/b.md": "**B**",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	// 既存の出力のパーミッションは保つ
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("old"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{filepath.Join(dir, "a.md"), filepath.Join(dir, "docs/b.md"), "--in-place", "--suffix", ".txt"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"a.txt":      "* A",
+		"docs/b.txt": "''B''",
+	}
+	for name, content := range expected {
+		output, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		if string(output) != content {
+			t.Errorf("%s: Expected %q, got %q", name, content, output)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatalf("Failed to stat output: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestConvertFilesSharedOptions(t *testing.T) {
+	defer resetRootCmd()
+
+	// プロジェクトユーザーの取得回数を数えるフェイクサーバー
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = io.WriteString(w, `[{"id":1,"userId":"suzuki","name":"鈴木","mailAddress":"taro@example.com"}]`)
+	}))
+	defer server.Close()
+
+	// 相対リンクは入力ファイルごとのディレクトリで解決する
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"a.md":      "@taro [c](c.md)",
+		"docs/b.md": "@taro [c](c.md)",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	rootCmd.SetArgs([]string{"a.md", "docs/b.md", "--in-place",
+		"--fetch-users", "PROJ", "--backlog-url", server.URL, "--api-key", "secret",
+		"--git-blob-url", "https://example.backlog.com/git/PROJ/repo/blob/main",
+	})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected users to be fetched once, got %d requests", n)
+	}
+	expected := map[string]string{
+		"a.backlog":      "@suzuki [[c:https://example.backlog.com/git/PROJ/repo/blob/main/c.md]]",
+		"docs/b.backlog": "@suzuki [[c:https://example.backlog.com/git/PROJ/repo/blob/main/docs/c.md]]",
+	}
+	for name, content := range expected {
+		output, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		if string(output) != content {
+			t.Errorf("%s: Expected %q, got %q", name, content, output)
+		}
+	}
+}
+
+func TestOutputTemplateIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "guide.md")
+	if err := os.WriteFile(inputPath, []byte("# Guide"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"-i", inputPath, "--output-template", "{{dir}}/out/{{name}}{{ext}}.txt"})
+	if err := os.MkdirAll(filepath.Join(dir, "out"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(dir, "out", "guide.md.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(output) != "* Guide" {
+		t.Errorf("Expected %q, got %q", "* Guide", output)
+	}
+}
+
+func TestConvertFilesWritesNothingOnError(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(inputPath, []byte("# A"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	inPlace = true
+	err := convertFiles(rootCmd, []string{inputPath, filepath.Join(dir, "missing.md")})
+	if err == nil {
+		t.Fatal("Expected an error for a missing input file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.backlog")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output to be written, got %v", err)
+	}
+}
+
+func TestConvertFilesFlagErrors(t *testing.T) {
+	defer resetRootCmd()
+
+	tests := []struct {
+		name     string
+		setup    func()
+		files    []string
+		expected string
+	}{
+		{name: "multiple inputs", setup: func() {}, files: []string{"a.md", "b.md"}, expected: "multiple input files require --in-place or --output-template"},
+		{name: "in-place and template", setup: func() { inPlace, outputTemplate = true, "{{name}}.txt" }, files: []string{"a.md"}, expected: "--in-place and --output-template cannot be used together"},
+		{name: "output file", setup: func() { inPlace, outputFile = true, "out.txt" }, files: []string{"a.md"}, expected: "--output cannot be used with --in-place, --output-template or multiple input files"},
+		{name: "no inputs", setup: func() { inPlace = true }, expected: "--in-place and --output-template require input files"},
+		{name: "same as input", setup: func() { inPlace, outputSuffix = true, ".md" }, files: []string{"a.md"}, expected: "a.md: output path is the same as the input"},
+		{name: "same output", setup: func() { outputTemplate = "out.txt" }, files: []string{"a.md", "b.md"}, expected: "a.md and b.md are both written to out.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRootCmd()
+			tt.setup()
+			err := convertFiles(rootCmd, tt.files)
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestExpandOutputTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		input    string
+		expected string
+	}{
+		{template: "{{dir}}/{{name}}.txt", input: "docs/guide.md", expected: "docs/guide.txt"},
+		{template: "out/{{name}}{{ext}}.backlog", input: "guide.markdown", expected: "out/guide.markdown.backlog"},
+		{template: "{{dir}}/{{name}}.txt", input: "guide.md", expected: "guide.txt"},
+	}
+
+	for _, tt := range tests {
+		output, err := expandOutputTemplate(tt.template, tt.input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if output != filepath.FromSlash(tt.expected) {
+			t.Errorf("Expected %q, got %q", tt.expected, output)
+		}
+	}
+
+	if _, err := expandOutputTemplate("{{base}}.txt", "a.md"); err == nil {
+		t.Error("Expected an error for an unknown placeholder")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+
+	if err := writeFileAtomic(path, []byte("new")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat output: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644, got %v", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the output file, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hookCheck  bool
+	hookSuffix string
+	hookForce  bool
+)
+
+// hookMarker はmd2backlogがインストールしたフックであることを示す行です（上書きしてよいかの判定に使います）
+const hookMarker = "# Installed by md2backlog hook install"
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Keep Backlog outputs of Markdown files up to date with a Git pre-commit hook",
+	Long: `Keep Backlog outputs of Markdown files up to date when committing.
+
+For each Markdown file (docs/a.md) the Backlog output is written next to it
+(docs/a.backlog, see --suffix). "hook install" installs a pre-commit hook that
+runs "hook run", which regenerates the outputs of staged Markdown files and
+stages them, or with --check fails the commit when an output is out of date.`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install [-- conversion flags]",
+	Short: "Install a Git pre-commit hook that runs \"md2backlog hook run\"",
+	Long: `Install a Git pre-commit hook that runs "md2backlog hook run".
+
+Conversion flags after "--" are passed to "hook run", e.g.
+  md2backlog hook install --check -- --toc macro --heading-offset 1`,
+	RunE: runHookInstall,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:   "run [files...]",
+	Short: "Regenerate Backlog outputs of staged Markdown files",
+	Long: `Regenerate the Backlog outputs of staged Markdown files and stage them.
+
+Without files, the staged Markdown files are converted from their staged
+content. With files (as passed by the pre-commit framework), the given
+Markdown files are converted from the working tree. With --check, nothing is
+written and the command fails when an output is missing or out of date.
+
+With --includes, included files are always read from the working tree, even
+when the including file is converted from its staged content. A warning is
+printed when the working tree has unstaged changes.`,
+	RunE: runHookRun,
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	repo, err := gitTopLevel(".")
+	if err != nil {
+		return err
+	}
+
+	runArgs := []string{"hook", "run"}
+	if hookCheck {
+		runArgs = append(runArgs, "--check")
+	}
+	if hookSuffix != defaultOutputSuffix {
+		runArgs = append(runArgs, "--suffix", hookSuffix)
+	}
+	runArgs = append(runArgs, args...)
+
+	path, err := installHook(repo, runArgs, hookForce)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(logWriter(), "Installed pre-commit hook: %s\n", path)
+	return nil
+}
+
+func runHookRun(cmd *cobra.Command, args []string) error {
+	repo, err := gitTopLevel(".")
+	if err != nil {
+		return err
+	}
+
+	// 変換では相対リンクやinclude指示をリポジトリ内のパスで解決するため、最上位のディレクトリに移動する
+	files := make([]string, len(args))
+	for i, arg := range args {
+		if files[i], err = filepath.Abs(arg); err != nil {
+			return err
+		}
+	}
+	if err := os.Chdir(repo); err != nil {
+		return err
+	}
+
+	return regenerateOutputs(repo, files, hookCheck, hookSuffix, logWriter())
+}
+
+// installHook はリポジトリにpre-commitフックをインストールし、そのパスを返します
+// md2backlog以外がインストールしたフックはforceを指定しない限り上書きしません
+func installHook(repo string, runArgs []string, force bool) (string, error) {
+	out, err := git(repo, "rev-parse", "--git-path", "hooks/pre-commit")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repo, path)
+	}
+
+	if current, err := os.ReadFile(path); err == nil && !force && !bytes.Contains(current, []byte(hookMarker)) {
+		return "", fmt.Errorf("pre-commit hook already exists: %s (use --force to overwrite)", path)
+	}
+
+	quoted := make([]string, len(runArgs))
+	for i, arg := range runArgs {
+		quoted[i] = shellQuote(arg)
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s\nexec md2backlog %s\n", hookMarker, strings.Join(quoted, " "))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", err
+	}
+	// 既存のファイルを上書きした場合はパーミッションが変わらないため、実行可能にする
+	return path, os.Chmod(path, 0755)
+}
+
+// regenerateOutputs はMarkdownファイルの変換結果を再生成してステージします
+// filesを指定しない場合はステージされたMarkdownファイルをステージされた内容で変換します
+// checkの場合は書き込まず、最新でない変換結果があればエラーを返します
+func regenerateOutputs(repo string, files []string, check bool, suffix string, log io.Writer) error {
+	staged := len(files) == 0
+	paths, err := hookFiles(repo, files, suffix)
+	if err != nil {
+		return err
+	}
+
+	// --fetch-users によるユーザーの取得などは、ファイルごとではなく1回だけ行う
+	shared, err := sharedConverterOptions()
+	if err != nil {
+		return err
+	}
+
+	// include指示で読み込むファイルはステージされた内容ではなく作業ツリーから読む
+	if staged && resolveIncludes && len(paths) > 0 {
+		if out, err := git(repo, "diff", "--name-only"); err == nil && len(bytes.TrimSpace(out)) > 0 {
+			fmt.Fprintln(log, "Warning: included files are read from the working tree, which has unstaged changes")
+		}
+	}
+
+	var updated, stale []string
+	for _, path := range paths {
+		var input []byte
+		if staged {
+			input, err = git(repo, "show", ":"+path)
+		} else {
+			input, err = os.ReadFile(filepath.Join(repo, path))
+		}
+		if err != nil {
+			return &readError{path: path, err: err}
+		}
+
+		result, err := convertFile(path, input, shared)
+		if err != nil {
+			return &conversionError{path: path, err: err}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(log, "Warning: %s: %s\n", path, warning)
+		}
+		if err := checkStrict(path, result.Warnings); err != nil {
+			return err
+		}
+
+		// ステージされた内容を変換した場合は、変換結果もステージされた内容と比べる
+		output := outputPathFor(path, suffix)
+		var upToDate bool
+		if staged {
+			current, err := git(repo, "show", ":"+output)
+			upToDate = err == nil && string(current) == result.Body
+		} else if upToDate, err = isUpToDate(filepath.Join(repo, output), result.Body); err != nil {
+			return err
+		}
+		if upToDate {
+			continue
+		}
+		if check {
+			stale = append(stale, output)
+			continue
+		}
+		if err := writeOutput(filepath.Join(repo, output), []byte(result.Body)); err != nil {
+			return err
+		}
+		updated = append(updated, output)
+	}
+
+	if len(stale) > 0 {
+		for _, output := range stale {
+			fmt.Fprintf(log, "Out of date: %s\n", output)
+		}
+		return fmt.Errorf("%d Backlog output(s) are out of date; run \"md2backlog hook run\" and stage them", len(stale))
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	if _, err := git(repo, append([]string{"add", "--"}, updated...)...); err != nil {
+		return err
+	}
+	for _, output := range updated {
+		fmt.Fprintf(log, "Updated: %s\n", output)
+	}
+	return nil
+}
+
+// hookFiles は変換するMarkdownファイルのリポジトリ内のパスを返します
+// filesを指定しない場合はステージされた（追加・変更された）Markdownファイルです
+// 変換結果のファイル（suffixが.mdなど）は変換しません
+func hookFiles(repo string, files []string, suffix string) ([]string, error) {
+	if len(files) == 0 {
+		out, err := git(repo, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+		if err != nil {
+			return nil, err
+		}
+		files = strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	} else {
+		for i, file := range files {
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				return nil, err
+			}
+			rel, err := filepath.Rel(repo, abs)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, fmt.Errorf("%s is outside the repository", file)
+			}
+			files[i] = filepath.ToSlash(rel)
+		}
+	}
+
+	var paths []string
+	for _, file := range files {
+		if file != "" && isMarkdownFile(file) && !strings.HasSuffix(file, suffix) {
+			paths = append(paths, file)
+		}
+	}
+	return paths, nil
+}
+
+// gitTopLevel はdirを含むGitリポジトリの最上位のディレクトリを返します
+func gitTopLevel(dir string) (string, error) {
+	out, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// git はdirでgitコマンドを実行し、その標準出力を返します
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&hookCheck, "check", false, "Fail the commit when outputs are out of date instead of regenerating them")
+	hookInstallCmd.Flags().StringVar(&hookSuffix, "suffix", defaultOutputSuffix, "File extension of Backlog outputs (docs/a.md is written to docs/a<suffix>)")
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Overwrite an existing pre-commit hook")
+
+	hookRunCmd.Flags().BoolVar(&hookCheck, "check", false, "Do not write outputs; fail when an output is missing or out of date")
+	hookRunCmd.Flags().StringVar(&hookSuffix, "suffix", defaultOutputSuffix, "File extension of Backlog outputs (docs/a.md is written to docs/a<suffix>)")
+	setupConversionFlags(hookRunCmd)
+
+	hookCmd.AddCommand(hookInstallCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newGitRepo はテスト用のGitリポジトリを作成し、ファイルを書き込んでステージする
+func newGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if _, err := git(repo, "init", "-q"); err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := git(repo, "add", name); err != nil {
+			t.Fatalf("Failed to stage file: %v", err)
+		}
+	}
+	return repo
+}
+
+// stagedContent はステージされたファイルの内容を返す（ステージされていない場合は空）
+func stagedContent(repo, path string) string {
+	out, err := git(repo, "show", ":"+path)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+func TestHookRun(t *testing.T) {
+	repo := newGitRepo(t, map[string]string{
+		"docs/a.md":  "# Title\n\n**bold**",
+		"README.txt": "not markdown",
+	})
+
+	if err := regenerateOutputs(repo, nil, false, defaultOutputSuffix, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "* Title\n''bold''"
+	if got := stagedContent(repo, "docs/a.backlog"); got != expected {
+		t.Errorf("Expected staged output %q, got %q", expected, got)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "README.backlog")); !os.IsNotExist(err) {
+		t.Errorf("Expected no output for a non-Markdown file")
+	}
+
+	// 最新になった後はチェックが通る
+	if err := regenerateOutputs(repo, nil, true, defaultOutputSuffix, io.Discard); err != nil {
+		t.Errorf("Expected outputs to be up to date, got %v", err)
+	}
+}
+
+func TestHookRunCheck(t *testing.T) {
+	repo := newGitRepo(t, map[string]string{
+		"a.md":      "# A",
+		"a.backlog": "* Old",
+		"b.md":      "# B",
+	})
+	// 作業ツリーだけを更新した変換結果はステージされていないため最新とみなさない
+	if err := os.WriteFile(filepath.Join(repo, "a.backlog"), []byte("* A"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var log strings.Builder
+	err := regenerateOutputs(repo, nil, true, defaultOutputSuffix, &log)
+	if err == nil || !strings.Contains(err.Error(), "2 Backlog output(s) are out of date") {
+		t.Fatalf("Expected out of date error, got %v", err)
+	}
+	for _, expected := range []string{"Out of date: a.backlog", "Out of date: b.backlog"} {
+		if !strings.Contains(log.String(), expected) {
+			t.Errorf("Expected log to contain %q, got %q", expected, log.String())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, "b.backlog")); !os.IsNotExist(err) {
+		t.Errorf("Expected --check not to write outputs")
+	}
+}
+
+func TestHookRunFiles(t *testing.T) {
+	repo := newGitRepo(t, map[string]string{"docs/a.md": "# A", "docs/b.md": "# B"})
+	// ファイルを指定した場合は作業ツリーの内容を変換する
+	if err := os.WriteFile(filepath.Join(repo, "docs/a.md"), []byte("# Edited"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	files := []string{filepath.Join(repo, "docs/a.md"), filepath.Join(repo, "docs/a.backlog.md")}
+	if err := regenerateOutputs(repo, files, false, ".backlog.md", io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(repo, "docs/a.backlog.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(output) != "* Edited" {
+		t.Errorf("Expected %q, got %q", "* Edited", output)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "docs/b.backlog.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected only the given files to be converted")
+	}
+
+	if _, err := hookFiles(repo, []string{filepath.Dir(repo)}, defaultOutputSuffix); err == nil {
+		t.Errorf("Expected an error for a path outside the repository")
+	}
+	// ".." で始まる名前のファイルはリポジトリの中にある
+	paths, err := hookFiles(repo, []string{filepath.Join(repo, "..notes.md")}, defaultOutputSuffix)
+	if err != nil || len(paths) != 1 || paths[0] != "..notes.md" {
+		t.Errorf("Expected [..notes.md], got %v (%v)", paths, err)
+	}
+}
+
+func TestHookRunStagedIncludes(t *testing.T) {
+	defer resetRootCmd()
+
+	repo := newGitRepo(t, map[string]string{
+		"a.md":    "<!-- include: part.md -->",
+		"part.md": "# Staged",
+	})
+	t.Chdir(repo)
+	resolveIncludes = true
+
+	var log strings.Builder
+	if err := regenerateOutputs(repo, nil, false, defaultOutputSuffix, &log); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(log.String(), "Warning") {
+		t.Errorf("Expected no warning without unstaged changes, got %q", log.String())
+	}
+
+	// ステージしていない変更がある場合は、作業ツリーから読み込むことを警告する
+	if err := os.WriteFile(filepath.Join(repo, "part.md"), []byte("# Unstaged"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	log.Reset()
+	if err := regenerateOutputs(repo, nil, false, defaultOutputSuffix, &log); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(log.String(), "Warning: included files are read from the working tree") {
+		t.Errorf("Expected a warning about the working tree, got %q", log.String())
+	}
+}
+
+func TestHookRunSharedOptions(t *testing.T) {
+	defer resetRootCmd()
+
+	// プロジェクトユーザーの取得回数を数えるフェイクサーバー
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = io.WriteString(w, `[{"id":1,"userId":"suzuki","name":"鈴木","mailAddress":"taro@example.com"}]`)
+	}))
+	defer server.Close()
+
+	repo := newGitRepo(t, map[string]string{"a.md": "@taro", "docs/b.md": "@taro"})
+	fetchUsers, backlogURL, apiKey = "PROJ", server.URL, "secret"
+
+	if err := regenerateOutputs(repo, nil, false, defaultOutputSuffix, io.Discard); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected users to be fetched once, got %d requests", n)
+	}
+	for _, name := range []string{"a.backlog", "docs/b.backlog"} {
+		if got := stagedContent(repo, name); got != "@suzuki" {
+			t.Errorf("%s: Expected staged output %q, got %q", name, "@suzuki", got)
+		}
+	}
+}
+
+func TestHookInstall(t *testing.T) {
+	repo := newGitRepo(t, nil)
+
+	path, err := installHook(repo, []string{"hook", "run", "--check", "--toc", "macro"}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read hook: %v", err)
+	}
+	if !strings.Contains(string(script), "exec md2backlog 'hook' 'run' '--check' '--toc' 'macro'\n") {
+		t.Errorf("Unexpected hook script: %q", script)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected the hook to be executable")
+	}
+
+	// md2backlogがインストールしたフックは上書きできる
+	if _, err := installHook(repo, []string{"hook", "run"}, false); err != nil {
+		t.Errorf("Expected to overwrite our own hook, got %v", err)
+	}
+
+	// 他のフックはforceを指定しない限り上書きしない
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	if _, err := installHook(repo, []string{"hook", "run"}, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected existing hook error, got %v", err)
+	}
+	if _, err := installHook(repo, []string{"hook", "run"}, true); err != nil {
+		t.Errorf("Expected --force to overwrite the hook, got %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -69,7 +71,12 @@ func TestStdinToStdoutIntegration(t *testing.T) {
 		{
 			name:     "lists and links",
 			input:    "- Item 1\n- [Link](url)",
-			expected: "- Item 1\n- \n[[Link:url]]",
+			expected: "- Item 1\n- [[Link:url]]",
+		},
+		{
+			name:     "blocks with blank lines in code",
+			input:    "Intro\n\n```\nfirst\n\nsecond\n```\n\n## Next\n\nOutro\n",
+			expected: "Intro\n\n>{code}\nfirst\n\nsecond\n{/code}<\n** Next\nOutro",
 		},
 	}
 
@@ -117,6 +124,208 @@ func TestStdinToStdoutIntegration(t *testing.T) {
 	}
 }
 
+// TestLinkRewritingIntegration は相対リンク書き換えフラグの統合テストを実行する
+func TestLinkRewritingIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "[API](../design/api.md#auth) and [setup](setup.md)",
+		"--doc-dir", "docs/guide",
+		"--wiki-page", "docs/design/api.md=設計/API",
+		"--git-blob-url", "https://example.backlog.com/git/PROJ/repo/blob/main",
+	)
+
+	expected := "[[API>設計/API]] and [[setup:https://example.backlog.com/git/PROJ/repo/blob/main/docs/guide/setup.md]]"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestAutoLinkIntegration は自動リンクフラグの統合テストを実行する
+func TestAutoLinkIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "<https://example.com> and <dev@example.com>", "--autolink", "bracket")
+
+	expected := "[[https://example.com:https://example.com]] and [[dev@example.com:mailto:dev@example.com]]"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestTOCIntegration は目次フラグの統合テストを実行する
+func TestTOCIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "[TOC]\n\n# Title", "--toc", "macro")
+
+	expected := "#contents\n* Title"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestHeadingOffsetIntegration は見出しレベル調整フラグの統合テストを実行する
+func TestHeadingOffsetIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "# Title\n## Section\n### Detail", "--heading-offset", "1", "--max-heading-level", "3")
+
+	expected := "** Title\n*** Section\n*** Detail"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestMarkdownFormatIntegration はMarkdown出力形式の統合テストを実行する
+func TestMarkdownFormatIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "# Title\n\n- [x] done\n- **bold**", "--format", "markdown")
+
+	expected := "# Title\n\n- ☑ done\n- **bold**"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestHTMLInputIntegration はHTML入力フラグの統合テストを実行する
+func TestHTMLInputIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "<h1>Title</h1><ul><li>one<li>two</ul><p><b>bold</b> &amp; <a href=\"https://example.com\">link</a></p>", "--from", "html")
+
+	expected := "* Title\n- one\n- two\n''bold'' & [[link:https://example.com]]"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestIncludesIntegration はinclude指示の展開フラグの統合テストを実行する
+func TestIncludesIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sections"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sections", "setup.md"), []byte("# Setup\n\nRun it\n"), 0644); err != nil {
+		t.Fatalf("Failed to write section: %v", err)
+	}
+	inputPath := filepath.Join(dir, "index.md")
+	if err := os.WriteFile(inputPath, []byte("# Guide\n\n<!-- include: sections/setup.md heading-offset=auto -->\n"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	outputPath := filepath.Join(dir, "out.txt")
+
+	rootCmd.SetArgs([]string{"-i", inputPath, "-o", outputPath, "--includes"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	expected := "* Guide\n** Setup\nRun it"
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestFootnotesIntegration は脚注フラグの統合テストを実行する
+func TestFootnotesIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "See RFC[^1].\n\n[^1]: RFC 2119", "--footnotes", "notes")
+
+	expected := "See RFC[1].\n\n''Notes''\n[1] RFC 2119"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestExtensionFlagsIntegration は定義リスト・Typographerフラグの統合テストを実行する
+func TestExtensionFlagsIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "Term\n: \"Quoted\" description", "--definition-list", "--typographer")
+
+	expected := "''Term''\n  “Quoted” description"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestCodeLanguageIntegration はコードブロックの言語名フラグの統合テストを実行する
+func TestCodeLanguageIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	output := runFileConversionTest(t, "```golang title=\"main.go\"\n```\n\n```tf\n```", "--code-language", "tf=hcl", "--code-title")
+
+	expected := "''main.go''\n>{code:go}\n{/code}<\n>{code:hcl}\n{/code}<"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestDiagramIntegration は図のコードブロックを変換するフラグの統合テストを実行する
+func TestDiagramIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	diagramDir := t.TempDir()
+	output := runFileConversionTest(t, "```mermaid caption=\"Flow\"\ngraph TD\n```\n\n```math\nx^2\n```",
+		"--diagram-command", "mermaid=cp {input} {output}",
+		"--diagram-dir", diagramDir,
+		"--diagram-caption",
+	)
+
+	images, err := filepath.Glob(filepath.Join(diagramDir, "mermaid-*.png"))
+	if err != nil || len(images) != 1 {
+		t.Fatalf("Expected one generated image, got %v (%v)", images, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(diagramDir, "*.txt")); len(leftovers) != 0 {
+		t.Errorf("Input files were not removed: %v", leftovers)
+	}
+
+	expected := "''Flow''\n![Flow](" + filepath.ToSlash(images[0]) + ")\n''math''\n>{code:math}\nx^2\n{/code}<"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestDiagramCommandChange はコマンドを変えた場合に生成済みの画像を再利用しないことを確認する
+func TestDiagramCommandChange(t *testing.T) {
+	defer resetRootCmd()
+
+	diagramDir := t.TempDir()
+	t.Setenv("DIAGRAM_THEME", "light")
+	var outputs []string
+	for _, command := range []string{
+		"mermaid=cp {input} {output}",
+		"mermaid=cat {input} {input} > {output}",
+		"mermaid=echo $DIAGRAM_THEME > {output}",
+	} {
+		resetRootCmd()
+		outputs = append(outputs, runFileConversionTest(t, "```mermaid\ngraph TD\n```",
+			"--diagram-command", command, "--diagram-dir", diagramDir))
+	}
+	// 環境変数の値を変えた場合も作り直す
+	t.Setenv("DIAGRAM_THEME", "dark")
+	resetRootCmd()
+	outputs = append(outputs, runFileConversionTest(t, "```mermaid\ngraph TD\n```",
+		"--diagram-command", "mermaid=echo $DIAGRAM_THEME > {output}", "--diagram-dir", diagramDir))
+
+	images, err := filepath.Glob(filepath.Join(diagramDir, "mermaid-*.png"))
+	if err != nil || len(images) != len(outputs) {
+		t.Fatalf("Expected %d generated images, got %v (%v)", len(outputs), images, err)
+	}
+	for i := 1; i < len(outputs); i++ {
+		if outputs[i] == outputs[i-1] {
+			t.Errorf("Expected a new image for command %d, got %q", i+1, outputs[i])
+		}
+	}
+}
+
 // TestVersionIntegration はバージョンコマンドの統合テストを実行する
 func TestVersionIntegration(t *testing.T) {
 	defer resetRootCmd()
@@ -246,12 +455,11 @@ func resetRootCmd() {
 	resetFlags()
 	rootCmd.SetArgs(nil)
 	rootCmd.ResetFlags()
-	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input Markdown file (default: stdin)")
-	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+	setupFlags(rootCmd)
 }
 
 // runFileConversionTest はファイル変換の統合テストを実行し、結果を返す
-func runFileConversionTest(t *testing.T, inputContent string) string {
+func runFileConversionTest(t *testing.T, inputContent string, extraArgs ...string) string {
 	// 一時ディレクトリを作成
 	tmpDir, err := os.MkdirTemp("", "md2backlog-integration-test")
 	if err != nil {
@@ -269,7 +477,7 @@ func runFileConversionTest(t *testing.T, inputContent string) string {
 	outputPath := filepath.Join(tmpDir, "output.txt")
 
 	// コマンド実行
-	rootCmd.SetArgs([]string{"-i", inputPath, "-o", outputPath})
+	rootCmd.SetArgs(append([]string{"-i", inputPath, "-o", outputPath}, extraArgs...))
 	err = rootCmd.Execute()
 	if err != nil {
 		t.Fatalf("Command execution failed: %v", err)
@@ -290,14 +498,144 @@ func captureStdout(t *testing.T, fn func()) string {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
+	// パイプのバッファを超える出力でも詰まらないよう、並行して読み取る
+	output := make(chan string)
+	go func() {
+		var buf strings.Builder
+		if _, err := io.Copy(&buf, r); err != nil {
+			t.Errorf("Failed to read stdout: %v", err)
+		}
+		output <- buf.String()
+	}()
+
 	fn()
 
 	w.Close()
 	os.Stdout = oldStdout
+	return strings.TrimSpace(<-output)
+}
+
+// TestStdinMatchesInputFile は標準入力からの変換が -i での変換と同じ結果になることを確認する
+func TestStdinMatchesInputFile(t *testing.T) {
+	defer resetRootCmd()
+
+	// 参照リンクの定義が分割の目安（64KB）より後ろにある入力
+	var input strings.Builder
+	input.WriteString("See [the spec][spec].\n\n")
+	for input.Len() < 96*1024 {
+		input.WriteString("A paragraph of filler text to make the input large.\n\n")
+	}
+	input.WriteString("[spec]: https://example.com/spec\n")
+
+	inputPath := filepath.Join(t.TempDir(), "input.md")
+	if err := os.WriteFile(inputPath, []byte(input.String()), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	run := func(args ...string) string {
+		resetRootCmd()
+		stdin, err := os.Open(inputPath)
+		if err != nil {
+			t.Fatalf("Failed to open input file: %v", err)
+		}
+		defer stdin.Close()
+		oldStdin := os.Stdin
+		os.Stdin = stdin
+		defer func() { os.Stdin = oldStdin }()
+
+		return captureStdout(t, func() {
+			rootCmd.SetArgs(args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("Command execution failed: %v", err)
+			}
+		})
+	}
+
+	fromStdin := run()
+	fromFile := run("-i", inputPath)
+	if !strings.HasPrefix(fromStdin, "See [[the spec:https://example.com/spec]].") {
+		t.Errorf("Expected the reference link to be resolved, got %q", fromStdin[:60])
+	}
+	if fromStdin != fromFile {
+		t.Errorf("Expected stdin output to match -i output (%d and %d bytes)", len(fromStdin), len(fromFile))
+	}
+
+	// --stream は分割して変換するが、参照リンクを含まない部分は同じ結果になる
+	streamed := run("--stream")
+	if !strings.HasSuffix(streamed, fromFile[len(fromFile)-200:]) {
+		t.Errorf("Expected streamed output to end like the -i output, got %q", streamed[len(streamed)-200:])
+	}
+}
+
+func TestJSONOutputIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	input := "---\nlabels: [release]\n---\n# Release 2.0\n\nSee [the issue](https://example.backlog.com/view/PROJ-12).\n\n![arch](img/arch.png)\n\n## Notes\n"
+	output := runFileConversionTest(t, input, "--output-format", "json")
+
+	var result jsonResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", output, err)
+	}
+	expected := jsonResult{
+		Body:        "* Release 2.0\nSee [[the issue:https://example.backlog.com/view/PROJ-12]].\n\n![arch](img/arch.png)\n** Notes",
+		Title:       "Release 2.0",
+		FrontMatter: map[string]any{"labels": []any{"release"}},
+		Headings:    []jsonHeading{{Level: 1, Text: "Release 2.0", Line: 4}, {Level: 2, Text: "Notes", Line: 10}},
+		Links:       []jsonLink{{Text: "the issue", URL: "https://example.backlog.com/view/PROJ-12", Issue: "PROJ-12", Line: 6}},
+		Images:      []jsonImage{{Alt: "arch", URL: "img/arch.png", Line: 8}},
+		Warnings:    []jsonWarning{},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestFrontMatterTextAndJSONOutput(t *testing.T) {
+	defer resetRootCmd()
+
+	input := "---\ntitle: Release\n---\n# Release 2.0\n\n<div>html</div>\n"
+	text := runFileConversionTest(t, input)
+	resetRootCmd()
+	output := runFileConversionTest(t, input, "--output-format", "json")
+
+	var result jsonResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", output, err)
+	}
+	// どちらの出力形式でもフロントマターは本文から除き、警告の行番号は入力ファイルの行に合わせる
+	if text != "* Release 2.0" || result.Body != text {
+		t.Errorf("Expected %q in both outputs, got %q and %q", "* Release 2.0", text, result.Body)
+	}
+	expected := []jsonWarning{{Line: 6, Message: "raw HTML is not supported and was removed"}}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Warnings)
+	}
+}
+
+func TestTitleFromH1Integration(t *testing.T) {
+	defer resetRootCmd()
+
+	input := "# Release 2.0\n\n## Notes\n"
+	text := runFileConversionTest(t, input, "--title-from-h1")
+	resetRootCmd()
+	output := runFileConversionTest(t, input, "--title-from-h1", "--output-format", "json")
+
+	var result jsonResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse output %q: %v", output, err)
+	}
+	// 最初のH1は本文から除き、JSONの件名にする
+	if text != "** Notes" || result.Body != text {
+		t.Errorf("Expected %q in both outputs, got %q and %q", "** Notes", text, result.Body)
+	}
+	if result.Title != "Release 2.0" {
+		t.Errorf("Expected title %q, got %q", "Release 2.0", result.Title)
+	}
+}
 
-	var buf strings.Builder
-	if _, err := io.Copy(&buf, r); err != nil {
-		t.Fatalf("Failed to read stdout: %v", err)
+func TestInvalidOutputFormat(t *testing.T) {
+	if err := validateOutputFormat("yaml"); err == nil {
+		t.Error("Expected an error for an unknown output format")
 	}
-	return strings.TrimSpace(buf.String())
 }
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"md2backlog/internal/converter"
+	"md2backlog/internal/render"
+)
+
+// 変換結果の出力形式（--output-format）
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// jsonResult は --output-format json で出力する変換結果です
+type jsonResult struct {
+	Body        string         `json:"body"`
+	Title       string         `json:"title"`
+	FrontMatter map[string]any `json:"frontMatter"`
+	Headings    []jsonHeading  `json:"headings"`
+	Links       []jsonLink     `json:"links"`
+	Images      []jsonImage    `json:"images"`
+	Warnings    []jsonWarning  `json:"warnings"`
+}
+
+type jsonHeading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Line  int    `json:"line,omitempty"`
+}
+
+type jsonLink struct {
+	Text     string `json:"text"`
+	URL      string `json:"url"`
+	WikiPage string `json:"wikiPage,omitempty"`
+	Issue    string `json:"issue,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+type jsonImage struct {
+	Alt  string `json:"alt"`
+	URL  string `json:"url"`
+	Line int    `json:"line,omitempty"`
+}
+
+type jsonWarning struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// validateOutputFormat は --output-format の値を検証します
+func validateOutputFormat(outputFormat string) error {
+	switch outputFormat {
+	case outputFormatText, outputFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown output format: %q (expected text or json)", outputFormat)
+}
+
+// convertJSON はファイルの内容を変換し、フロントマターと見出し・リンク・画像の一覧を含む結果を返します
+// 本文と行番号はテキストの出力と同じく、フロントマターを除いて変換した結果です
+func convertJSON(path string, input []byte, opts []converter.Option) (*converter.Result, *jsonResult, error) {
+	output := &jsonResult{}
+	if frontMatter, _ := converter.ExtractFrontMatter(string(input)); frontMatter != "" {
+		values, err := render.ParseValues([]byte(frontMatter))
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing front matter: %w", err)
+		}
+		output.FrontMatter = values
+	}
+
+	result, err := convertDocument(path, input, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := result.Metadata
+
+	output.Body = result.Body
+	output.Title = result.Title
+	output.Headings = make([]jsonHeading, len(metadata.Headings))
+	for i, heading := range metadata.Headings {
+		output.Headings[i] = jsonHeading{Level: heading.Level, Text: heading.Text, Line: heading.Line}
+	}
+	// 件名を本文から取り除いていない場合は、最初のH1（なければ最初の見出し）を件名とする
+	if output.Title == "" && len(output.Headings) > 0 {
+		output.Title = output.Headings[0].Text
+		for _, heading := range output.Headings {
+			if heading.Level == 1 {
+				output.Title = heading.Text
+				break
+			}
+		}
+	}
+	output.Links = make([]jsonLink, len(metadata.Links))
+	for i, link := range metadata.Links {
+		output.Links[i] = jsonLink{Text: link.Text, URL: link.URL, WikiPage: link.WikiPage, Issue: link.Issue, Line: link.Line}
+	}
+	output.Images = make([]jsonImage, len(metadata.Images))
+	for i, image := range metadata.Images {
+		output.Images[i] = jsonImage{Alt: image.Alt, URL: image.URL, Line: image.Line}
+	}
+	output.Warnings = make([]jsonWarning, len(result.Warnings))
+	for i, warning := range result.Warnings {
+		output.Warnings[i] = jsonWarning{Line: warning.Line, Message: warning.Message}
+	}
+	return result, output, nil
+}
+
+// marshalJSONResult は変換結果をインデントしたJSONにします
+func marshalJSONResult(output *jsonResult) (string, error) {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
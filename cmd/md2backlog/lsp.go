@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+
+	"md2backlog/internal/converter"
+	"md2backlog/internal/lsp"
+
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server that reports conversion diagnostics",
+	Long: `Run a Language Server Protocol server over stdio for Markdown documents.
+
+The server publishes conversion warnings (unsupported constructs, lossy
+conversions) as diagnostics, offers a "Copy as Backlog" code action
+(command md2backlog.copyAsBacklog, which returns the converted text), and
+answers the custom request md2backlog/preview with the converted body,
+title and warnings.
+
+Conversion options can be passed as initializationOptions using the same
+fields as the serve command's JSON options, e.g. {"titleFromFirstH1": true}.`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	return lsp.NewServer(version, converter.Settings{}).Run(os.Stdin, os.Stdout)
+}
+
+func init() {
+	// エディタが付けることの多い --stdio を受け付ける（標準入出力以外には対応していない）
+	lspCmd.Flags().Bool("stdio", true, "Communicate over stdin/stdout")
+	rootCmd.AddCommand(lspCmd)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 
 	"md2backlog/internal/converter"
 
@@ -13,64 +16,348 @@ import (
 var version = "0.1.0"
 
 var (
-	inputFile  string
-	outputFile string
+	inputFile    string
+	outputFile   string
+	outputFormat string
+	streamInput  bool
+
+	inPlace        bool
+	outputSuffix   string
+	outputTemplate string
+
+	from       string
+	format     string
+	baseURL    string
+	gitBlobURL string
+	docDir     string
+	wikiPages  map[string]string
+	tocStyle   string
+	autoLinks  string
+	footnotes  string
+
+	definitionList  bool
+	typographer     bool
+	linkify         bool
+	resolveIncludes bool
+
+	languageAliases map[string]string
+	codeTitle       bool
+
+	headingOffset   int
+	maxHeadingLevel int
+	titleFromH1     bool
 )
 
 var rootCmd = &cobra.Command{
-	Use:     "md2backlog",
+	Use:     "md2backlog [files...]",
 	Short:   "Convert Markdown to Backlog notation",
 	Long:    "A CLI tool to convert Markdown text to Backlog notation using AST parsing",
 	Version: version,
-	Run:     runConvert,
+	// 引数は入力ファイルです（サブコマンド名の誤りは存在しない入力ファイルとしてエラーになります）
+	Args: cobra.ArbitraryArgs,
+	RunE: runConvert,
+	// エラーはmainで終了コードとともに表示する
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
-func runConvert(cmd *cobra.Command, args []string) {
-	var input []byte
-	var err error
+func runConvert(cmd *cobra.Command, args []string) error {
+	// 図を生成した一時ディレクトリは、変換やアップロードの失敗時も含めて終了時に削除する
+	defer removeDiagramTempDir()
 
-	// 入力の読み取り
-	if inputFile != "" {
-		input, err = os.ReadFile(inputFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading input file: %v\n", err)
-			os.Exit(1)
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return &usageError{err: err}
+	}
+
+	// 入力ファイルごとに出力する場合や複数の入力ファイルは、すべて変換してから書き込む
+	if inPlace || outputTemplate != "" || len(args) > 1 || (len(args) == 1 && inputFile != "") {
+		files := args
+		if inputFile != "" {
+			files = append([]string{inputFile}, args...)
+		}
+		return convertFiles(cmd, files)
+	}
+	if len(args) == 1 {
+		inputFile = args[0]
+	}
+
+	// 変換オプションの組み立て
+	opts, err := converterOptions(inputFile)
+	if err != nil {
+		return err
+	}
+
+	// --stream を指定した標準入力から標準出力への変換は、入力全体を読み込まずに逐次変換する
+	// 参照リンクの定義は分割した部分の中でしか解決されないため、指定がなければ -i と同じく一括で変換する
+	// （添付ファイルのアップロードには変換結果の添付ファイル一覧が、include指示の展開とJSONの出力には文書全体が必要なため、
+	// --strict では警告があれば何も出力しないため一括で変換する）
+	if streamInput && inputFile == "" && outputFile == "" && !uploadAttachments && !resolveIncludes && outputFormat == outputFormatText && !strict {
+		out := bufio.NewWriter(os.Stdout)
+		warnings, err := converter.ConvertTo(out, os.Stdin, opts...)
+		if err == nil {
+			err = out.Flush()
 		}
-	} else {
-		input, err = io.ReadAll(os.Stdin)
+		printWarnings(warnings)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
-			os.Exit(1)
+			return &conversionError{path: inputName(""), err: err}
 		}
+		return nil
+	}
+
+	// 入力の読み取り
+	input, err := readInput(inputFile)
+	if err != nil {
+		return err
 	}
 
 	// Markdownをバックログ記法に変換
-	result, err := converter.Convert(string(input))
+	result, body, err := convertOutput(inputFile, input, opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
-		os.Exit(1)
+		return &conversionError{path: inputName(inputFile), err: err}
+	}
+
+	// 変換時の警告を標準エラー出力に表示（--strict の場合は出力を書き込まずに失敗する）
+	printWarnings(result.Warnings)
+	if err := checkStrict(inputFile, result.Warnings); err != nil {
+		return err
+	}
+
+	// ローカルファイルのアップロード
+	if uploadAttachments {
+		if err := uploadLocalAttachments(context.Background(), result.Attachments); err != nil {
+			return fmt.Errorf("uploading attachments: %w", err)
+		}
 	}
 
 	// 出力の書き込み
 	if outputFile != "" {
-		err = os.WriteFile(outputFile, []byte(result), 0644)
+		return writeOutput(outputFile, []byte(body))
+	}
+	fmt.Print(body)
+	return nil
+}
+
+// convertOutput はファイルの内容を変換し、変換結果と --output-format に応じた出力の内容を返します
+func convertOutput(path string, input []byte, opts []converter.Option) (*converter.Result, string, error) {
+	if outputFormat != outputFormatJSON {
+		result, err := convertDocument(path, input, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, result.Body, nil
+	}
+
+	result, output, err := convertJSON(path, input, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := marshalJSONResult(output)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, body, nil
+}
+
+// printWarnings は変換時の警告を標準エラー出力に表示します（--quiet の場合は表示しません）
+func printWarnings(warnings []converter.Warning) {
+	for _, warning := range warnings {
+		fmt.Fprintf(logWriter(), "Warning: %s\n", warning)
+	}
+}
+
+// converterOptions はフラグの値から変換オプションを組み立てます
+// documentPath は相対リンクの解決に使う入力文書のパスです（標準入力の場合は空）
+func converterOptions(documentPath string) ([]converter.Option, error) {
+	opts, err := sharedConverterOptions()
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, documentOptions(documentPath)...), nil
+}
+
+// documentOptions は入力文書ごとに異なる変換オプション（相対リンクの解決）を組み立てます
+func documentOptions(documentPath string) []converter.Option {
+	if baseURL == "" && gitBlobURL == "" && len(wikiPages) == 0 {
+		return nil
+	}
+	dir := docDir
+	if dir == "" && documentPath != "" && !filepath.IsAbs(documentPath) {
+		dir = filepath.ToSlash(filepath.Dir(documentPath))
+	}
+	return []converter.Option{converter.WithLinkResolver(&converter.PathLinkResolver{
+		BaseURL:     baseURL,
+		DocumentDir: dir,
+		WikiPages:   wikiPages,
+		GitBlobURL:  gitBlobURL,
+	})}
+}
+
+// sharedConverterOptions はすべての入力文書に共通の変換オプションを組み立てます
+// --fetch-users によるユーザーの取得やマップファイルの読み込みは、ここで1回だけ行います
+func sharedConverterOptions() ([]converter.Option, error) {
+	var opts []converter.Option
+
+	inputFormat, err := converter.ParseInputFormat(from)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+	if inputFormat != converter.InputMarkdown {
+		opts = append(opts, converter.WithInputFormat(inputFormat))
+	}
+
+	outputFormat, err := converter.ParseFormat(format)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+	opts = append(opts, converter.WithFormat(outputFormat))
+
+	if err := validateUploadFlags(); err != nil {
+		return nil, &usageError{err: err}
+	}
+	if uploadAttachments {
+		if outputFormat == converter.FormatMarkdown {
+			return nil, &usageError{err: errors.New("--upload-attachments cannot be used with --format markdown")}
+		}
+		opts = append(opts, converter.WithLocalAttachments())
+	}
+
+	autoLinkStyle, err := converter.ParseAutoLinkStyle(autoLinks)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+	if autoLinkStyle != converter.AutoLinkBare {
+		opts = append(opts, converter.WithAutoLinkStyle(autoLinkStyle))
+	}
+
+	style, err := converter.ParseTOCStyle(tocStyle)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+	if style != converter.TOCNone {
+		opts = append(opts, converter.WithTOC(style))
+	}
+
+	footnoteStyle, err := converter.ParseFootnoteStyle(footnotes)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+	if footnoteStyle != converter.FootnoteNone {
+		opts = append(opts, converter.WithFootnotes(footnoteStyle))
+	}
+
+	if definitionList {
+		opts = append(opts, converter.WithDefinitionList())
+	}
+	if typographer {
+		opts = append(opts, converter.WithTypographer())
+	}
+	if linkify {
+		opts = append(opts, converter.WithLinkify())
+	}
+
+	if len(languageAliases) > 0 {
+		opts = append(opts, converter.WithLanguageAliases(languageAliases))
+	}
+	if codeTitle {
+		opts = append(opts, converter.WithCodeTitle())
+	}
+
+	diagramOpts, err := diagramOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, diagramOpts...)
+
+	if userMapFile != "" || fetchUsers != "" {
+		users, err := loadUserMap(context.Background())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
-			os.Exit(1)
+			return nil, err
 		}
-	} else {
-		fmt.Print(result)
+		opts = append(opts, converter.WithMentions(users))
 	}
+	if enableEmoji || emojiMapFile != "" {
+		var custom map[string]string
+		if emojiMapFile != "" {
+			custom, err = readStringMap(emojiMapFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, converter.WithEmoji(custom))
+	}
+
+	if headingOffset != 0 {
+		opts = append(opts, converter.WithHeadingOffset(headingOffset))
+	}
+	if maxHeadingLevel != converter.MaxHeadingLevel {
+		opts = append(opts, converter.WithMaxHeadingLevel(maxHeadingLevel))
+	}
+	if titleFromH1 {
+		opts = append(opts, converter.WithTitleFromFirstH1())
+	}
+
+	return opts, nil
+}
+
+// setupFlags はルートコマンドのフラグを登録します
+func setupFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input Markdown file (default: stdin; more files can be given as arguments)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().BoolVar(&inPlace, "in-place", false, "Write each output next to its input file (docs/a.md is written to docs/a<suffix>)")
+	cmd.Flags().StringVar(&outputSuffix, "suffix", defaultOutputSuffix, "File extension of outputs written with --in-place")
+	cmd.Flags().StringVar(&outputTemplate, "output-template", "", "Output path for each input file, e.g. '{{dir}}/{{name}}.txt' ({{dir}}, {{name}} and {{ext}} of the input)")
+	cmd.Flags().BoolVar(&streamInput, "stream", false, "Convert stdin to stdout block by block without reading it all first (reference link definitions only apply within about 64KB of input)")
+	cmd.Flags().StringVar(&outputFormat, "output-format", outputFormatText, "Output format: text (converted body) or json (body with title, front matter, headings, links, images and warnings)")
+	setupConversionFlags(cmd)
+	cmd.Flags().BoolVar(&uploadAttachments, "upload-attachments", false, "Upload referenced local files to Backlog and rewrite them to #image/#attach")
+	cmd.Flags().StringVar(&backlogURL, "backlog-url", "", "Backlog space URL (e.g. https://example.backlog.com)")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "Backlog API key (default: $BACKLOG_API_KEY)")
+	cmd.Flags().StringVar(&issueKey, "issue", "", "Issue key to attach uploaded files to")
+	cmd.Flags().StringVar(&wikiID, "wiki", "", "Wiki page ID to attach uploaded files to")
+	cmd.Flags().StringVar(&fetchUsers, "fetch-users", "", "Fetch users of this Backlog project to map @handles (enables mention conversion)")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Do not print warnings and progress messages (errors are still printed)")
+}
+
+// setupConversionFlags は変換オプションのフラグを登録します（ファイルを一括で変換するサブコマンドと共有します）
+func setupConversionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&from, "from", "markdown", "Input format: markdown or html (e.g. Confluence exports and HTML email)")
+	cmd.Flags().StringVar(&format, "format", "backlog", "Output format: backlog (Backlog notation) or markdown (Markdown subset rendered by Backlog)")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "Base URL for resolving relative links and images")
+	cmd.Flags().StringVar(&gitBlobURL, "git-blob-url", "", "Backlog Git viewer blob URL prefix for relative links (e.g. https://space.backlog.com/git/PROJ/repo/blob/main)")
+	cmd.Flags().StringVar(&docDir, "doc-dir", "", "Repository-relative directory of the input document (default: directory of --input)")
+	cmd.Flags().StringVar(&autoLinks, "autolink", "bare", "Output of <https://...> autolinks and linkified URLs: bare (plain URL) or bracket ([[url:url]])")
+	cmd.Flags().StringVar(&tocStyle, "toc", "", "Replace [TOC] / <!-- toc --> markers with a table of contents: macro (#contents) or list")
+	cmd.Flags().StringVar(&footnotes, "footnotes", "", "Convert [^1] footnotes: notes ([1] markers with a Notes section at the end) or inline (expanded in parentheses)")
+	cmd.Flags().BoolVar(&definitionList, "definition-list", false, "Convert definition lists (term followed by \": description\" lines)")
+	cmd.Flags().BoolVar(&typographer, "typographer", false, "Replace straight quotes, dashes and ... with typographic punctuation")
+	cmd.Flags().BoolVar(&linkify, "linkify", false, "Treat bare URLs and email addresses as links")
+	cmd.Flags().BoolVar(&resolveIncludes, "includes", false, "Expand <!-- include: path --> and {{< include path >}} directives before converting (lines=3-10, heading-offset=N|auto)")
+	cmd.Flags().StringToStringVar(&languageAliases, "code-language", nil, "Map a code block language alias to a language Backlog highlights (alias=language, empty language for none)")
+	cmd.Flags().BoolVar(&codeTitle, "code-title", false, "Show the title of code blocks (title=\"main.go\" or lang:file in the info string) above the block")
+	cmd.Flags().StringToStringVar(&diagramCommands, "diagram-command", nil, "Render code blocks of a language to an image with a local command (lang=command, {input} and {output} are replaced with file paths)")
+	cmd.Flags().BoolVar(&diagramCaption, "diagram-caption", false, "Show a caption above math/mermaid/plantuml blocks (from caption=\"...\" in the info string, default: the language)")
+	cmd.Flags().StringVar(&diagramDir, "diagram-dir", "", "Directory for images generated by --diagram-command (default: a temporary directory with --upload-attachments)")
+	cmd.Flags().IntVar(&headingOffset, "heading-offset", 0, "Shift heading levels by this amount (e.g. 1 renders # as **)")
+	cmd.Flags().IntVar(&maxHeadingLevel, "max-heading-level", converter.MaxHeadingLevel, "Clamp heading levels to this maximum")
+	cmd.Flags().BoolVar(&titleFromH1, "title-from-h1", false, "Remove the first H1 from the body and use it as the title (reported as \"title\" with --output-format json)")
+	cmd.Flags().StringToStringVar(&wikiPages, "wiki-page", nil, "Map a repository-relative .md path to a Backlog wiki page name (path=PageName)")
+	cmd.Flags().StringVar(&userMapFile, "user-map", "", "JSON file mapping @handles to Backlog users (enables mention conversion)")
+	cmd.Flags().BoolVar(&enableEmoji, "emoji", false, "Convert :emoji: shortcodes to Unicode emoji")
+	cmd.Flags().StringVar(&emojiMapFile, "emoji-map", "", "JSON file with additional :emoji: shortcodes (implies --emoji)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail without writing output when the conversion produces warnings (exit code 7)")
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input Markdown file (default: stdin)")
-	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+	setupFlags(rootCmd)
+	// フラグの誤りはサブコマンドでも使い方の誤りの終了コードにする
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		printError(err)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"md2backlog/internal/backlog"
+	"md2backlog/internal/converter"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultMaxCommentLength はBacklogのコメントの文字数の上限です
+const defaultMaxCommentLength = 100000
+
+var (
+	postSplit     bool
+	postMaxLength int
+)
+
+var postCmd = &cobra.Command{
+	Use:   "post [file]",
+	Short: "Convert Markdown and post it as a comment on a Backlog issue",
+	Long: `Convert a Markdown file (default: stdin) and post it as a comment on --issue.
+
+When the converted text is longer than --max-length, the command fails unless
+--split is given. With --split, the text is split at block boundaries (never
+inside code blocks or tables) into several comments headed "(1/3)", "(2/3)", ...
+
+Example:
+  md2backlog post report.md --issue PROJ-12 --backlog-url https://example.backlog.com --split`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPost,
+}
+
+func runPost(cmd *cobra.Command, args []string) error {
+	defer removeDiagramTempDir()
+
+	if err := validatePostFlags(); err != nil {
+		return &usageError{err: err}
+	}
+
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	}
+	input, err := readInput(path)
+	if err != nil {
+		return err
+	}
+
+	shared, err := sharedConverterOptions()
+	if err != nil {
+		return err
+	}
+	result, err := convertFile(path, input, shared)
+	if err != nil {
+		return &conversionError{path: inputName(path), err: err}
+	}
+	printWarnings(result.Warnings)
+	if err := checkStrict(path, result.Warnings); err != nil {
+		return err
+	}
+
+	parts, err := commentParts(result.Body)
+	if err != nil {
+		return &conversionError{path: inputName(path), err: err}
+	}
+
+	client := backlog.NewClient(backlogURL, backlogAPIKey())
+	for i, part := range parts {
+		if _, err := client.AddComment(context.Background(), issueKey, part); err != nil {
+			return fmt.Errorf("posting comment %d/%d: %w", i+1, len(parts), err)
+		}
+	}
+	fmt.Fprintf(logWriter(), "Posted %d comment(s) to %s\n", len(parts), issueKey)
+	return nil
+}
+
+// validatePostFlags はコメントの投稿に必要なフラグを検証します
+func validatePostFlags() error {
+	if backlogURL == "" {
+		return errors.New("--backlog-url is required")
+	}
+	if backlogAPIKey() == "" {
+		return errors.New("--api-key or BACKLOG_API_KEY is required")
+	}
+	if issueKey == "" {
+		return errors.New("--issue is required")
+	}
+	if postMaxLength <= 0 {
+		return fmt.Errorf("--max-length must be greater than 0: %d", postMaxLength)
+	}
+	return nil
+}
+
+// commentParts は変換結果を投稿するコメントに分けます
+// --split を指定しない場合、--max-length を超える変換結果はエラーにします
+func commentParts(body string) ([]string, error) {
+	if postSplit {
+		return converter.SplitByLength(body, postMaxLength)
+	}
+	if n := utf8.RuneCountInString(body); n > postMaxLength {
+		return nil, fmt.Errorf("converted text has %d characters, more than --max-length %d (use --split to post several comments)", n, postMaxLength)
+	}
+	return []string{body}, nil
+}
+
+func init() {
+	postCmd.Flags().StringVar(&backlogURL, "backlog-url", "", "Backlog space URL (e.g. https://example.backlog.com)")
+	postCmd.Flags().StringVar(&apiKey, "api-key", "", "Backlog API key (default: $BACKLOG_API_KEY)")
+	postCmd.Flags().StringVar(&issueKey, "issue", "", "Issue key to post the comment to")
+	postCmd.Flags().BoolVar(&postSplit, "split", false, "Split text longer than --max-length into numbered comments")
+	postCmd.Flags().IntVar(&postMaxLength, "max-length", defaultMaxCommentLength, "Maximum number of characters in a comment")
+	setupConversionFlags(postCmd)
+	rootCmd.AddCommand(postCmd)
+}
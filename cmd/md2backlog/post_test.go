@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPostSplitIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	// Backlog APIのフェイクサーバー
+	var comments []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/issues/PROJ-1/comments" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		comments = append(comments, r.PostForm.Get("content"))
+		_, _ = io.WriteString(w, `{"id":1}`)
+	}))
+	defer server.Close()
+
+	inputPath := filepath.Join(t.TempDir(), "report.md")
+	input := "# 調査結果\n\n原因を特定しました。\n\n```go\nfunc main() {}\n```\n\n対応は以上です。\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{
+		"post", inputPath,
+		"--backlog-url", server.URL,
+		"--api-key", "secret",
+		"--issue", "PROJ-1",
+		"--split", "--max-length", "40",
+	})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	expected := []string{
+		"(1/3)\n* 調査結果\n原因を特定しました。",
+		"(2/3)\n>{code:go}\nfunc main() {}\n{/code}<",
+		"(3/3)\n対応は以上です。",
+	}
+	if !reflect.DeepEqual(comments, expected) {
+		t.Errorf("Expected %q, got %q", expected, comments)
+	}
+}
+
+func TestPostInvalidMaxLength(t *testing.T) {
+	defer func() { postSplit, postMaxLength = false, defaultMaxCommentLength }()
+	defer resetRootCmd()
+
+	for _, length := range []string{"0", "-1"} {
+		t.Run(length, func(t *testing.T) {
+			rootCmd.SetArgs([]string{
+				"post",
+				"--backlog-url", "https://example.backlog.com",
+				"--api-key", "secret",
+				"--issue", "PROJ-1",
+				"--split", "--max-length", length,
+			})
+			err := rootCmd.Execute()
+			if code := exitCode(err); code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d (%v)", exitUsage, code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"md2backlog/internal/render"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderTemplate string
+	renderVars     map[string]string
+	renderValues   string
+	renderOutput   string
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Expand a Markdown template and convert it to Backlog notation",
+	Long: `Expand a Markdown template written with Go text/template and convert the result.
+
+Values are read from --values (YAML) and --var (which take precedence) and are
+referenced as {{ .name }}. Templates can also use these functions:
+
+  date "2006-01-02"   Current date and time in a Go layout
+  issue "PROJ-123"    Link to the issue (with --backlog-url; otherwise the key)
+  include "a.md"      Expand another template file relative to the current one
+  env "NAME"          Value of an environment variable
+
+Example:
+  md2backlog render --template bug.md --var version=1.2 --var env=prod`,
+	Args: cobra.NoArgs,
+	RunE: runRender,
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	markdown, err := renderMarkdown()
+	if err != nil {
+		return err
+	}
+
+	shared, err := sharedConverterOptions()
+	if err != nil {
+		return err
+	}
+	result, err := convertFile(renderTemplate, []byte(markdown), shared)
+	if err != nil {
+		return &conversionError{path: renderTemplate, err: err}
+	}
+	printWarnings(result.Warnings)
+	if err := checkStrict(renderTemplate, result.Warnings); err != nil {
+		return err
+	}
+
+	if renderOutput != "" {
+		return writeOutput(renderOutput, []byte(result.Body))
+	}
+	fmt.Print(result.Body)
+	return nil
+}
+
+// renderMarkdown はテンプレートを値で展開したMarkdownを返します
+func renderMarkdown() (string, error) {
+	values := map[string]any{}
+	if renderValues != "" {
+		data, err := readInput(renderValues)
+		if err != nil {
+			return "", err
+		}
+		if values, err = render.ParseValues(data); err != nil {
+			return "", &readError{path: renderValues, err: err}
+		}
+	}
+	for name, value := range renderVars {
+		values[name] = value
+	}
+
+	if _, err := os.Stat(renderTemplate); errors.Is(err, fs.ErrNotExist) {
+		return "", &inputNotFoundError{path: renderTemplate}
+	}
+	renderer := &render.Renderer{Values: values, BacklogURL: backlogURL}
+	markdown, err := renderer.RenderFile(renderTemplate)
+	if err != nil {
+		return "", &conversionError{path: renderTemplate, err: err}
+	}
+	return markdown, nil
+}
+
+func init() {
+	renderCmd.Flags().StringVarP(&renderTemplate, "template", "t", "", "Markdown template file")
+	renderCmd.Flags().StringToStringVar(&renderVars, "var", nil, "Template value (name=value, repeatable)")
+	renderCmd.Flags().StringVar(&renderValues, "values", "", "YAML file with template values")
+	renderCmd.Flags().StringVarP(&renderOutput, "output", "o", "", "Output file (default: stdout)")
+	renderCmd.Flags().StringVar(&backlogURL, "backlog-url", "", "Backlog space URL for issue links (e.g. https://example.backlog.com)")
+	setupConversionFlags(renderCmd)
+	_ = renderCmd.MarkFlagRequired("template")
+	rootCmd.AddCommand(renderCmd)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"bug.md":      "# {{ .title }} v{{ .version }}\n\n{{ include \"env.md\" }}\n\n関連: {{ issue .related }}",
+		"env.md":      "- 環境: {{ .env }}",
+		"values.yaml": "title: 障害報告\nenv: staging\nrelated: PROJ-7\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	outputPath := filepath.Join(dir, "out.txt")
+
+	rootCmd.SetArgs([]string{
+		"render", "--template", filepath.Join(dir, "bug.md"),
+		"--values", filepath.Join(dir, "values.yaml"),
+		"--var", "version=1.2", "--var", "env=prod",
+		"--backlog-url", "https://example.backlog.com",
+		"-o", outputPath,
+	})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	expected := "* 障害報告 v1.2\n- 環境: prod\n関連: [[PROJ-7:https://example.backlog.com/view/PROJ-7]]"
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
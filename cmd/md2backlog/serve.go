@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"md2backlog/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr         string
+	serveMaxBodyBytes int64
+	serveMaxBatchSize int
+	serveLogFormat    string
+)
+
+// shutdownTimeout は終了時に処理中のリクエストを待つ時間です
+const shutdownTimeout = 10 * time.Second
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP server that converts Markdown to Backlog notation",
+	Long: `Run an HTTP server exposing the conversion as a service.
+
+  POST /convert        Convert one document (raw Markdown body with options as query
+                       parameters, or JSON {"markdown": "...", "options": {...}})
+  POST /convert/batch  Convert several documents ({"documents": [...], "options": {...}})
+  GET  /healthz        Health check
+  GET  /version        Version`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	logger, err := newLogger(serveLogFormat)
+	if err != nil {
+		return &usageError{err: err}
+	}
+
+	httpServer := &http.Server{
+		Handler: server.New(server.Config{
+			Version:      version,
+			MaxBodyBytes: serveMaxBodyBytes,
+			MaxBatchSize: serveMaxBatchSize,
+			Logger:       logger,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return err
+	}
+
+	// SIGINT・SIGTERMを受け取ったら処理中のリクエストを待ってから終了する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("listening", slog.String("addr", listener.Addr().String()), slog.String("version", version))
+	if err := serve(ctx, httpServer, listener); err != nil {
+		return err
+	}
+	logger.Info("stopped")
+	return nil
+}
+
+// serve はctxが終了するまでリクエストを処理します
+// ctxの終了後は処理中のリクエストが終わる（またはshutdownTimeoutが過ぎる）のを待ってから戻ります
+func serve(ctx context.Context, httpServer *http.Server, listener net.Listener) error {
+	shutdownDone := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdownDone <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	// Serveは Shutdown の開始と同時に戻るため、Shutdownの完了を待つ
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shutdownDone
+}
+
+// newLogger はログの形式に応じたロガーを生成します
+func newLogger(format string) (*slog.Logger, error) {
+	switch format {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, nil)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil)), nil
+	}
+	return nil, fmt.Errorf("unknown log format: %q", format)
+}
+
+// setupServeFlags はserveコマンドのフラグを登録します
+func setupServeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().Int64Var(&serveMaxBodyBytes, "max-body-bytes", server.DefaultMaxBodyBytes, "Maximum request body size in bytes")
+	cmd.Flags().IntVar(&serveMaxBatchSize, "max-batch-size", server.DefaultMaxBatchSize, "Maximum number of documents in a batch request")
+	cmd.Flags().StringVar(&serveLogFormat, "log-format", "text", "Request log format: text or json")
+}
+
+func init() {
+	setupServeFlags(serveCmd)
+	rootCmd.AddCommand(serveCmd)
+}
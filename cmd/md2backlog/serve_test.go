@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeWaitsForShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "done")
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, httpServer, listener) }()
+
+	responded := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responded <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responded <- string(body)
+	}()
+
+	<-started
+	cancel()
+	// 処理中のリクエストが終わるまでは戻らない
+	select {
+	case err := <-served:
+		t.Fatalf("Expected serve to wait for the in-flight request, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-served; err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if body := <-responded; body != "done" {
+		t.Errorf("Expected %q, got %q", "done", body)
+	}
+}
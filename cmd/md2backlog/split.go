@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"md2backlog/internal/converter"
+	"md2backlog/internal/include"
+	"md2backlog/internal/render"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitLevel       int
+	splitOutDir      string
+	splitSuffix      string
+	splitKeepHeading bool
+)
+
+// splitManifestName は分割した変換結果の一覧を書き込むファイルの名前です
+const splitManifestName = "manifest.json"
+
+var splitCmd = &cobra.Command{
+	Use:   "split [file]",
+	Short: "Split a Markdown file at headings into one Backlog output per section",
+	Long: `Split a Markdown file at headings of --level and convert each section separately.
+
+Each section is written to <out-dir>/<slug><suffix>, where the slug is made
+from the heading text. Content before the first heading is written to
+index<suffix>. A manifest.json listing the sections is written as well.
+
+Front matter maps sections to wiki pages or issues in the manifest:
+
+  ---
+  wiki: Release/2.0       # each section becomes the wiki page Release/2.0/<title>
+  project: PROJ           # or: each section becomes a new issue in PROJ
+  sections:
+    export-api:           # by slug or title
+      issue: PROJ-12      # update an existing issue
+    認証の改善: Release/2.0/Auth   # wiki page name
+  ---`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSplit,
+}
+
+// splitTarget は節の変換結果の投稿先です
+type splitTarget struct {
+	Wiki    string `json:"wiki,omitempty"`
+	Issue   string `json:"issue,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// splitEntry はmanifest.jsonに書き込む節の情報です
+type splitEntry struct {
+	Title string `json:"title,omitempty"`
+	Slug  string `json:"slug"`
+	File  string `json:"file"`
+	splitTarget
+}
+
+// splitManifest はmanifest.jsonの内容です
+type splitManifest struct {
+	Sections []splitEntry `json:"sections"`
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	defer removeDiagramTempDir()
+
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	}
+	input, err := readInput(path)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := splitDocument(path, input, splitOutDir, logWriter())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(logWriter(), "Wrote %d section(s) to %s\n", len(manifest.Sections), splitOutDir)
+	return nil
+}
+
+// splitDocument は文書を節に分割して変換し、変換結果とmanifest.jsonをoutDirに書き込みます
+// 途中の節で変換に失敗した場合に一部だけを書き込まないよう、すべての節を変換してから書き込みます
+func splitDocument(path string, input []byte, outDir string, log io.Writer) (*splitManifest, error) {
+	opts, err := converterOptions(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := inputName(path)
+	frontMatter, markdown := converter.ExtractFrontMatter(string(input))
+	targets, err := parseSplitTargets(frontMatter)
+	if err != nil {
+		return nil, &conversionError{path: name, err: fmt.Errorf("parsing front matter: %w", err)}
+	}
+
+	// 取り込んだ文書の見出しでも分割できるよう、分割の前にinclude指示を展開する
+	if resolveIncludes {
+		resolved, err := include.Resolve(path, []byte(markdown))
+		if err != nil {
+			return nil, &conversionError{path: name, err: err}
+		}
+		markdown = string(resolved)
+	}
+
+	sections, err := converter.SplitSections(markdown, splitLevel, opts...)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+
+	manifest := &splitManifest{Sections: []splitEntry{}}
+	bodies := make([]string, len(sections))
+	for i, section := range sections {
+		body := section.Body
+		if splitKeepHeading {
+			body = section.Heading + body
+		}
+		result, err := converter.ConvertDetailed(body, opts...)
+		if err != nil {
+			return nil, &conversionError{path: name, err: fmt.Errorf("section %q: %w", section.Slug, err)}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(log, "Warning: %s: %s\n", section.Slug, warning)
+		}
+		if err := checkStrict(path, result.Warnings); err != nil {
+			return nil, err
+		}
+
+		bodies[i] = result.Body
+		manifest.Sections = append(manifest.Sections, splitEntry{
+			Title:       section.Title,
+			Slug:        section.Slug,
+			File:        section.Slug + splitSuffix,
+			splitTarget: targets.forSection(section),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, &writeError{path: outDir, err: err}
+	}
+	for i, entry := range manifest.Sections {
+		if err := writeOutput(filepath.Join(outDir, entry.File), []byte(bodies[i])); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeOutput(filepath.Join(outDir, splitManifestName), append(data, '\n')); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// splitTargets はフロントマターで指定した節の投稿先です
+type splitTargets struct {
+	// wikiPrefix は節をWikiにする場合のページ名の接頭辞です
+	wikiPrefix string
+	// project は節を課題にする場合のプロジェクトキーです
+	project string
+	// sections はSlugまたは見出しのテキストごとの投稿先です
+	sections map[string]splitTarget
+}
+
+// parseSplitTargets はフロントマターから節の投稿先を読み取ります
+func parseSplitTargets(frontMatter string) (*splitTargets, error) {
+	targets := &splitTargets{sections: map[string]splitTarget{}}
+	if frontMatter == "" {
+		return targets, nil
+	}
+	values, err := render.ParseValues([]byte(frontMatter))
+	if err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if value, exists := values["wiki"]; exists {
+		if targets.wikiPrefix, ok = value.(string); !ok {
+			return nil, fmt.Errorf("wiki must be a string")
+		}
+	}
+	if value, exists := values["project"]; exists {
+		if targets.project, ok = value.(string); !ok {
+			return nil, fmt.Errorf("project must be a string")
+		}
+	}
+	if targets.wikiPrefix != "" && targets.project != "" {
+		return nil, fmt.Errorf("wiki and project cannot be used together")
+	}
+
+	value, exists := values["sections"]
+	if !exists {
+		return targets, nil
+	}
+	sections, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("sections must be a mapping")
+	}
+	for name, value := range sections {
+		switch value := value.(type) {
+		case string:
+			targets.sections[name] = splitTarget{Wiki: value}
+		case map[string]any:
+			var target splitTarget
+			for key, field := range value {
+				s, ok := field.(string)
+				if !ok {
+					return nil, fmt.Errorf("sections.%s.%s must be a string", name, key)
+				}
+				switch key {
+				case "wiki":
+					target.Wiki = s
+				case "issue":
+					target.Issue = s
+				case "project":
+					target.Project = s
+				default:
+					return nil, fmt.Errorf("sections.%s: unknown key %q", name, key)
+				}
+			}
+			targets.sections[name] = target
+		default:
+			return nil, fmt.Errorf("sections.%s must be a string or a mapping", name)
+		}
+	}
+	return targets, nil
+}
+
+// forSection は節の投稿先を返します
+// sectionsでの指定（Slug、見出しのテキストの順）を優先し、なければ見出しのある節にwikiまたはprojectを適用します
+func (t *splitTargets) forSection(section converter.Section) splitTarget {
+	if target, ok := t.sections[section.Slug]; ok {
+		return target
+	}
+	if target, ok := t.sections[section.Title]; ok && section.Title != "" {
+		return target
+	}
+	switch {
+	case section.Title == "":
+		return splitTarget{}
+	case t.wikiPrefix != "":
+		return splitTarget{Wiki: strings.TrimSuffix(t.wikiPrefix, "/") + "/" + section.Title}
+	case t.project != "":
+		return splitTarget{Project: t.project}
+	}
+	return splitTarget{}
+}
+
+func init() {
+	splitCmd.Flags().IntVar(&splitLevel, "level", 2, "Heading level to split at (1-6)")
+	splitCmd.Flags().StringVar(&splitOutDir, "out-dir", "", "Directory to write the outputs and manifest.json to")
+	splitCmd.Flags().StringVar(&splitSuffix, "suffix", defaultOutputSuffix, "File extension of the outputs")
+	splitCmd.Flags().BoolVar(&splitKeepHeading, "keep-heading", false, "Keep the section heading at the top of each output")
+	setupConversionFlags(splitCmd)
+	_ = splitCmd.MarkFlagRequired("out-dir")
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plan.md")
+	input := "---\nproject: PROJ\nsections:\n  export-api:\n    issue: PROJ-12\n  認証の改善: Release/Auth\n---\n# リリース計画\n\n## 認証の改善\n\n**SSO** 対応\n\n## Export API\n\n- CSV\n\n## その他\n\n未定\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	outDir := filepath.Join(dir, "out")
+
+	rootCmd.SetArgs([]string{"split", inputPath, "--out-dir", outDir, "--keep-heading"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	outputs := map[string]string{
+		"index.backlog":      "* リリース計画",
+		"認証の改善.backlog":      "** 認証の改善\n''SSO'' 対応",
+		"export-api.backlog": "** Export API\n- CSV",
+		"その他.backlog":        "** その他\n未定",
+	}
+	for name, expected := range outputs {
+		output, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		if string(output) != expected {
+			t.Errorf("%s: Expected %q, got %q", name, expected, output)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, splitManifestName))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest splitManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	expected := []splitEntry{
+		{Slug: "index", File: "index.backlog"},
+		{Title: "認証の改善", Slug: "認証の改善", File: "認証の改善.backlog", splitTarget: splitTarget{Wiki: "Release/Auth"}},
+		{Title: "Export API", Slug: "export-api", File: "export-api.backlog", splitTarget: splitTarget{Issue: "PROJ-12"}},
+		{Title: "その他", Slug: "その他", File: "その他.backlog", splitTarget: splitTarget{Project: "PROJ"}},
+	}
+	if !reflect.DeepEqual(manifest.Sections, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, manifest.Sections)
+	}
+}
+
+func TestSplitWritesNothingOnFailure(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plan.md")
+	// 2つ目の節だけが警告になる（--strict で失敗する）
+	input := "## 最初\n\n本文\n\n## 次\n\n<div>HTML</div>\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	outDir := filepath.Join(dir, "out")
+
+	rootCmd.SetArgs([]string{"split", inputPath, "--out-dir", outDir, "--strict", "--quiet"})
+	err := rootCmd.Execute()
+	if code := exitCode(err); code != exitStrictViolation {
+		t.Fatalf("Expected exit code %d, got %d (%v)", exitStrictViolation, code, err)
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be created, got %v", outDir, err)
+	}
+}
+
+func TestSplitResolvesReferenceLinks(t *testing.T) {
+	defer resetRootCmd()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "doc.md")
+	input := "## 概要\n\nSee [spec][s].\n\n## 参考\n\n- [spec][s]\n\n[s]: https://example.com/spec\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	outDir := filepath.Join(dir, "out")
+
+	rootCmd.SetArgs([]string{"split", inputPath, "--out-dir", outDir, "--keep-heading=false", "--quiet"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	outputs := map[string]string{
+		"概要.backlog": "See [[spec:https://example.com/spec]].",
+		"参考.backlog": "- [[spec:https://example.com/spec]]",
+	}
+	for name, expected := range outputs {
+		output, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		if string(output) != expected {
+			t.Errorf("%s: Expected %q, got %q", name, expected, output)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"md2backlog/internal/backlog"
+	"md2backlog/internal/converter"
+)
+
+var (
+	uploadAttachments bool
+	backlogURL        string
+	apiKey            string
+	issueKey          string
+	wikiID            string
+)
+
+// validateUploadFlags は添付ファイルのアップロードに必要なフラグを検証します
+func validateUploadFlags() error {
+	if !uploadAttachments {
+		return nil
+	}
+	if backlogURL == "" {
+		return errors.New("--backlog-url is required with --upload-attachments")
+	}
+	if backlogAPIKey() == "" {
+		return errors.New("--api-key or BACKLOG_API_KEY is required with --upload-attachments")
+	}
+	if (issueKey == "") == (wikiID == "") {
+		return errors.New("exactly one of --issue or --wiki is required with --upload-attachments")
+	}
+	return nil
+}
+
+// backlogAPIKey はフラグまたは環境変数からAPIキーを取得します
+func backlogAPIKey() string {
+	if apiKey != "" {
+		return apiKey
+	}
+	return os.Getenv("BACKLOG_API_KEY")
+}
+
+// uploadLocalAttachments は本文から参照されたローカルファイルをアップロードし、課題またはWikiに添付します
+func uploadLocalAttachments(ctx context.Context, attachments []converter.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	// 相対パスは入力ファイルのディレクトリ（標準入力の場合はカレントディレクトリ）を基準にする
+	baseDir := "."
+	if inputFile != "" {
+		baseDir = filepath.Dir(inputFile)
+	}
+
+	// 文書の外を指すパスがあれば、何もアップロードしないうちに失敗させる
+	paths := make([]string, len(attachments))
+	for i, attachment := range attachments {
+		path, err := attachmentPath(baseDir, attachment)
+		if err != nil {
+			return err
+		}
+		paths[i] = path
+	}
+
+	client := backlog.NewClient(backlogURL, backlogAPIKey())
+	ids := make([]int, 0, len(attachments))
+	for i, attachment := range attachments {
+		id, err := uploadFile(ctx, client, paths[i], attachment.Name)
+		if err != nil {
+			return fmt.Errorf("uploading %s: %w", attachment.Path, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if issueKey != "" {
+		return client.AttachToIssue(ctx, issueKey, ids)
+	}
+	return client.AttachToWiki(ctx, wikiID, ids)
+}
+
+// attachmentPath は添付ファイルを読み込むパスを返します
+// 先頭が "/" のパスはリポジトリルート（カレントディレクトリ）からのパスとして扱い、
+// 文書のディレクトリとリポジトリルートのどちらの外にもあるファイル（../../secret.pem など）は読み込みません
+// --diagram-commandで生成した画像は一時ディレクトリにあるため、そのまま使います
+func attachmentPath(baseDir string, attachment converter.Attachment) (string, error) {
+	if attachment.Generated {
+		return filepath.FromSlash(attachment.Path), nil
+	}
+
+	var path string
+	if strings.HasPrefix(attachment.Path, "/") {
+		path = filepath.Join(".", filepath.FromSlash(attachment.Path))
+	} else {
+		path = filepath.Join(baseDir, filepath.FromSlash(attachment.Path))
+	}
+	if !isWithinDir(".", path) && !isWithinDir(baseDir, path) {
+		return "", fmt.Errorf("attachment %s is outside the document and repository directories", attachment.Path)
+	}
+	return path, nil
+}
+
+// isWithinDir はpathがdirまたはその下にあるかどうかを判定します
+func isWithinDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// uploadFile は1つのファイルをアップロードし、添付ファイルIDを返します
+func uploadFile(ctx context.Context, client *backlog.Client, path, name string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	attachment, err := client.UploadAttachment(ctx, name, file)
+	if err != nil {
+		return 0, err
+	}
+	return attachment.ID, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"md2backlog/internal/converter"
+)
+
+func TestUploadAttachmentsIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	// Backlog APIのフェイクサーバー
+	var mu sync.Mutex
+	var uploaded []string
+	var attachedIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/space/attachment":
+			_, header, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Failed to read uploaded file: %v", err)
+				return
+			}
+			uploaded = append(uploaded, header.Filename)
+			fmt.Fprintf(w, `{"id":%d,"name":%q,"size":1}`, len(uploaded), header.Filename)
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/v2/issues/PROJ-1":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("Failed to parse form: %v", err)
+			}
+			attachedIDs = r.PostForm["attachmentId[]"]
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tmpDir, err := os.MkdirTemp("", "md2backlog-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "img"), 0755); err != nil {
+		t.Fatalf("Failed to create image dir: %v", err)
+	}
+	for _, name := range []string{"img/flow.png", "spec.pdf"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	inputPath := filepath.Join(tmpDir, "input.md")
+	inputContent := "![diagram](./img/flow.png) [spec](./spec.pdf)"
+	if err := os.WriteFile(inputPath, []byte(inputContent), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	outputPath := filepath.Join(tmpDir, "output.txt")
+
+	rootCmd.SetArgs([]string{
+		"-i", inputPath, "-o", outputPath,
+		"--upload-attachments",
+		"--backlog-url", server.URL,
+		"--api-key", "secret",
+		"--issue", "PROJ-1",
+	})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	outputBytes, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	expected := "#image(flow.png) #attach(spec.pdf)"
+	if string(outputBytes) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(outputBytes))
+	}
+
+	if strings.Join(uploaded, ",") != "flow.png,spec.pdf" {
+		t.Errorf("Unexpected uploads: %v", uploaded)
+	}
+	if strings.Join(attachedIDs, ",") != "1,2" {
+		t.Errorf("Unexpected attachment IDs: %v", attachedIDs)
+	}
+}
+
+func TestUploadAttachmentsRemovesDiagramTempDir(t *testing.T) {
+	defer resetRootCmd()
+
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "input.md")
+	// HTMLブロックの警告で --strict の変換がアップロードの前に失敗する
+	if err := os.WriteFile(inputPath, []byte("```mermaid\ngraph TD\n```\n\n<div>html</div>\n"), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+	// 図を生成したディレクトリをコマンドから書き出す
+	marker := filepath.Join(tmpDir, "dir.txt")
+
+	rootCmd.SetArgs([]string{
+		"-i", inputPath, "-o", filepath.Join(tmpDir, "output.txt"),
+		"--upload-attachments",
+		"--backlog-url", "https://example.backlog.com",
+		"--api-key", "secret",
+		"--issue", "PROJ-1",
+		"--diagram-command", "mermaid=cp {input} {output} && dirname {output} > " + shellQuote(marker),
+		"--strict", "--quiet",
+	})
+	if err := rootCmd.Execute(); exitCode(err) != exitStrictViolation {
+		t.Fatalf("Expected a strict violation, got %v", err)
+	}
+
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("Diagram command was not run: %v", err)
+	}
+	diagramDir := strings.TrimSpace(string(data))
+	if _, err := os.Stat(diagramDir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", diagramDir, err)
+	}
+}
+
+func TestAttachmentPath(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+
+	tests := []struct {
+		name       string
+		baseDir    string
+		attachment converter.Attachment
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:       "relative to the document",
+			baseDir:    "docs",
+			attachment: converter.Attachment{Path: "img/flow.png"},
+			expected:   filepath.Join("docs", "img", "flow.png"),
+		},
+		{
+			name:       "parent directory inside the repository",
+			baseDir:    "docs",
+			attachment: converter.Attachment{Path: "../assets/logo.png"},
+			expected:   filepath.Join("assets", "logo.png"),
+		},
+		{
+			name:       "leading slash is the repository root",
+			baseDir:    "docs",
+			attachment: converter.Attachment{Path: "/home/u/.ssh/id_rsa.pub"},
+			expected:   filepath.Join("home", "u", ".ssh", "id_rsa.pub"),
+		},
+		{
+			name:       "leading slash cannot leave the repository root",
+			baseDir:    "docs",
+			attachment: converter.Attachment{Path: "/../secret.pem"},
+			wantErr:    true,
+		},
+		{
+			name:       "parent directories outside the repository",
+			baseDir:    "docs",
+			attachment: converter.Attachment{Path: "../../secret.pem"},
+			wantErr:    true,
+		},
+		{
+			name:       "document outside the repository",
+			baseDir:    filepath.Join(root, "..", "other"),
+			attachment: converter.Attachment{Path: "img/flow.png"},
+			expected:   filepath.Join(root, "..", "other", "img", "flow.png"),
+		},
+		{
+			name:       "generated diagram images are trusted",
+			baseDir:    "docs",
+			attachment: converter.Attachment{Path: "/tmp/diagrams/mermaid.png", Generated: true},
+			expected:   filepath.FromSlash("/tmp/diagrams/mermaid.png"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := attachmentPath(tt.baseDir, tt.attachment)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %q", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, path)
+			}
+		})
+	}
+}
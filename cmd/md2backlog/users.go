@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"md2backlog/internal/backlog"
+)
+
+var (
+	userMapFile  string
+	fetchUsers   string
+	enableEmoji  bool
+	emojiMapFile string
+)
+
+// loadUserMap はAPIから取得したユーザーと設定ファイルからメンションの対応表を組み立てます
+// 設定ファイルの対応はAPIから取得した対応より優先されます
+func loadUserMap(ctx context.Context) (map[string]string, error) {
+	users := map[string]string{}
+
+	if fetchUsers != "" {
+		if backlogURL == "" || backlogAPIKey() == "" {
+			return nil, &usageError{err: errors.New("--backlog-url and --api-key (or BACKLOG_API_KEY) are required with --fetch-users")}
+		}
+		fetched, err := backlog.NewClient(backlogURL, backlogAPIKey()).ProjectUsers(ctx, fetchUsers)
+		if err != nil {
+			return nil, fmt.Errorf("fetching users: %w", err)
+		}
+		// ユーザーIDとメールアドレスのローカル部をハンドルとみなす
+		for _, user := range fetched {
+			if local, _, ok := strings.Cut(user.MailAddress, "@"); ok && local != "" {
+				users[local] = user.UserID
+			}
+			users[user.UserID] = user.UserID
+		}
+	}
+
+	if userMapFile != "" {
+		fromFile, err := readStringMap(userMapFile)
+		if err != nil {
+			return nil, err
+		}
+		for handle, user := range fromFile {
+			users[handle] = user
+		}
+	}
+
+	return users, nil
+}
+
+// readStringMap は文字列同士の対応表をJSONファイルから読み込みます
+func readStringMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &inputNotFoundError{path: path}
+	}
+	if err != nil {
+		return nil, &readError{path: path, err: err}
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, &readError{path: path, err: fmt.Errorf("parsing JSON: %w", err)}
+	}
+	return m, nil
+}
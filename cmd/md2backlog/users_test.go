@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMentionAndEmojiIntegration(t *testing.T) {
+	defer resetRootCmd()
+
+	// プロジェクトユーザーを返すフェイクサーバー
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/projects/PROJ/users" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `[{"id":1,"userId":"suzuki","name":"鈴木","mailAddress":"taro@example.com"}]`)
+	}))
+	defer server.Close()
+
+	tmpDir, err := os.MkdirTemp("", "md2backlog-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	userMapPath := filepath.Join(tmpDir, "users.json")
+	if err := os.WriteFile(userMapPath, []byte(`{"octocat":"yamada"}`), 0644); err != nil {
+		t.Fatalf("Failed to write user map: %v", err)
+	}
+
+	output := runFileConversionTest(t, "@octocat @taro @suzuki :rocket:",
+		"--user-map", userMapPath,
+		"--fetch-users", "PROJ",
+		"--backlog-url", server.URL,
+		"--api-key", "secret",
+		"--emoji",
+	)
+
+	expected := "@yamada @suzuki @suzuki 🚀"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
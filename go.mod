@@ -2,9 +2,12 @@ module md2backlog
 
 go 1.24.5
 
+require (
+	github.com/spf13/cobra v1.9.1
+	github.com/yuin/goldmark v1.7.12
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.9.1 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
-	github.com/yuin/goldmark v1.7.12 // indirect
 )
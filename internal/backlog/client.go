@@ -0,0 +1,183 @@
+// Package backlog はBacklog API v2の最小限のクライアントを提供します
+package backlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client はBacklog APIのクライアントです
+type Client struct {
+	// BaseURL はスペースのURLです（例: https://example.backlog.com）
+	BaseURL string
+	// APIKey はAPIキーです
+	APIKey string
+	// HTTPClient はリクエストに使用するHTTPクライアントです（nilの場合はhttp.DefaultClient）
+	HTTPClient *http.Client
+}
+
+// NewClient は新しいClientを生成します
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		APIKey:  apiKey,
+	}
+}
+
+// Attachment はアップロード済みの添付ファイルを表します
+type Attachment struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// User はBacklogのユーザーを表します
+type User struct {
+	ID          int    `json:"id"`
+	UserID      string `json:"userId"`
+	Name        string `json:"name"`
+	MailAddress string `json:"mailAddress"`
+}
+
+// Comment は課題のコメントを表します
+type Comment struct {
+	ID      int    `json:"id"`
+	Content string `json:"content"`
+}
+
+// APIError はBacklog APIが返したエラーを表します
+type APIError struct {
+	StatusCode int
+	Messages   []string
+}
+
+func (e *APIError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("backlog API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("backlog API error: status %d: %s", e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+// UploadAttachment はファイルをスペースにアップロードします
+// 課題やWikiに添付するには、返されたIDをAttachToIssue/AttachToWikiに渡します
+func (c *Client) UploadAttachment(ctx context.Context, name string, r io.Reader) (*Attachment, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var attachment Attachment
+	if err := c.do(ctx, http.MethodPost, "/api/v2/space/attachment", writer.FormDataContentType(), &body, &attachment); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// AttachToIssue はアップロード済みのファイルを課題に添付します
+func (c *Client) AttachToIssue(ctx context.Context, issueIDOrKey string, attachmentIDs []int) error {
+	form := attachmentForm(attachmentIDs)
+	return c.doForm(ctx, http.MethodPatch, "/api/v2/issues/"+url.PathEscape(issueIDOrKey), form, nil)
+}
+
+// AttachToWiki はアップロード済みのファイルをWikiページに添付します
+func (c *Client) AttachToWiki(ctx context.Context, wikiID string, attachmentIDs []int) error {
+	form := attachmentForm(attachmentIDs)
+	return c.doForm(ctx, http.MethodPost, "/api/v2/wikis/"+url.PathEscape(wikiID)+"/attachments", form, nil)
+}
+
+// AddComment は課題にコメントを追加します
+func (c *Client) AddComment(ctx context.Context, issueIDOrKey, content string) (*Comment, error) {
+	form := url.Values{"content": {content}}
+	var comment Comment
+	if err := c.doForm(ctx, http.MethodPost, "/api/v2/issues/"+url.PathEscape(issueIDOrKey)+"/comments", form, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ProjectUsers はプロジェクトに参加しているユーザーの一覧を取得します
+func (c *Client) ProjectUsers(ctx context.Context, projectIDOrKey string) ([]User, error) {
+	var users []User
+	if err := c.do(ctx, http.MethodGet, "/api/v2/projects/"+url.PathEscape(projectIDOrKey)+"/users", "", nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// attachmentForm は添付ファイルIDのフォームを生成します
+func attachmentForm(attachmentIDs []int) url.Values {
+	form := url.Values{}
+	for _, id := range attachmentIDs {
+		form.Add("attachmentId[]", strconv.Itoa(id))
+	}
+	return form
+}
+
+// doForm はフォーム形式のリクエストを送信します
+func (c *Client) doForm(ctx context.Context, method, path string, form url.Values, result any) error {
+	return c.do(ctx, method, path, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), result)
+}
+
+// do はAPIリクエストを送信し、レスポンスをresultにデコードします
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader, result any) error {
+	endpoint := c.BaseURL + path + "?" + url.Values{"apiKey": {c.APIKey}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return decodeAPIError(resp)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// decodeAPIError はエラーレスポンスをAPIErrorに変換します
+func decodeAPIError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var payload struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err == nil {
+		for _, e := range payload.Errors {
+			apiErr.Messages = append(apiErr.Messages, e.Message)
+		}
+	}
+	return apiErr
+}
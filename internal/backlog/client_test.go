@@ -0,0 +1,138 @@
+package backlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/space/attachment" {
+			t.Errorf("予期しないリクエスト: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("apiKey") != "secret" {
+			t.Errorf("APIキーが送信されていません")
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("ファイルの読み取りに失敗しました: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "flow.png" || string(content) != "PNG" {
+			t.Errorf("予期しないファイル: %q %q", header.Filename, content)
+		}
+
+		_, _ = io.WriteString(w, `{"id":42,"name":"flow.png","size":3}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "secret")
+	attachment, err := client.UploadAttachment(context.Background(), "flow.png", strings.NewReader("PNG"))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := Attachment{ID: 42, Name: "flow.png", Size: 3}
+	if *attachment != expected {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, *attachment)
+	}
+}
+
+func TestAttachToIssueAndWiki(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("フォームの解析に失敗しました: %v", err)
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+strings.Join(r.PostForm["attachmentId[]"], ","))
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	if err := client.AttachToIssue(context.Background(), "PROJ-1", []int{1, 2}); err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if err := client.AttachToWiki(context.Background(), "10", []int{3}); err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := []string{
+		"PATCH /api/v2/issues/PROJ-1 1,2",
+		"POST /api/v2/wikis/10/attachments 3",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, requests)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"errors":[{"message":"Authenticate error.","code":11}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "wrong")
+	err := client.AttachToIssue(context.Background(), "PROJ-1", []int{1})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("APIErrorが返されませんでした: %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Messages[0] != "Authenticate error." {
+		t.Errorf("予期しないエラー: %+v", apiErr)
+	}
+}
+
+func TestProjectUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v2/projects/PROJ/users" {
+			t.Errorf("予期しないリクエスト: %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `[{"id":1,"userId":"yamada","name":"山田","mailAddress":"yamada@example.com"}]`)
+	}))
+	defer server.Close()
+
+	users, err := NewClient(server.URL, "secret").ProjectUsers(context.Background(), "PROJ")
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := User{ID: 1, UserID: "yamada", Name: "山田", MailAddress: "yamada@example.com"}
+	if len(users) != 1 || users[0] != expected {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, users)
+	}
+}
+
+func TestAddComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/issues/PROJ-1/comments" {
+			t.Errorf("予期しないリクエスト: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("フォームの解析に失敗しました: %v", err)
+		}
+		if content := r.PostForm.Get("content"); content != "* 見出し" {
+			t.Errorf("予期しない本文: %q", content)
+		}
+		_, _ = io.WriteString(w, `{"id":7,"content":"* 見出し"}`)
+	}))
+	defer server.Close()
+
+	comment, err := NewClient(server.URL, "secret").AddComment(context.Background(), "PROJ-1", "* 見出し")
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := Comment{ID: 7, Content: "* 見出し"}
+	if *comment != expected {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, *comment)
+	}
+}
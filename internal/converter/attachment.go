@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Attachment は本文から参照されているローカルファイルを表します
+type Attachment struct {
+	// Path はMarkdown上のパス（変換対象文書からの相対パス、先頭が "/" の場合はリポジトリルートからのパス）です
+	// CodeBlockHookが生成した画像の場合はフックが返したパスです
+	Path string
+	// Name はBacklog上の添付ファイル名です（別のパスのファイルと同じ名前の場合は flow-1.png のように番号を付けます）
+	Name string
+	// Image は画像として参照されているかどうかです
+	Image bool
+	// Generated はCodeBlockHookが生成したファイルかどうかです
+	// 文書に書かれたパスと違い、文書やリポジトリの外（一時ディレクトリなど）にあっても読み込んでかまいません
+	Generated bool
+}
+
+// attachmentCollector はローカルファイルへの参照を収集します
+type attachmentCollector struct {
+	attachments []Attachment
+	seen        map[string]int
+	// names は登録済みの添付ファイル名です（Backlog上で同じ名前にならないようにします）
+	names map[string]bool
+}
+
+// newAttachmentCollector は新しいattachmentCollectorを生成します
+func newAttachmentCollector() *attachmentCollector {
+	return &attachmentCollector{seen: map[string]int{}, names: map[string]bool{}}
+}
+
+// localFile はリンク先がローカルファイルであれば添付ファイルとして登録し、その名前を返します
+// Markdownファイルへのリンクは添付ではなくリンクの書き換え対象のため除外します
+func (c *attachmentCollector) localFile(destination string, image bool) (string, bool) {
+	if c == nil || !isRelativeLink(destination) {
+		return "", false
+	}
+
+	linkPath, _ := splitLinkSuffix(destination)
+	if unescaped, err := url.PathUnescape(linkPath); err == nil {
+		linkPath = unescaped
+	}
+	if path.Ext(linkPath) == "" || (!image && isMarkdownPath(linkPath)) {
+		return "", false
+	}
+
+	return c.add(path.Clean(linkPath), image, false), true
+}
+
+// generatedFile はCodeBlockHookが生成したローカルファイルを添付ファイルとして登録し、その名前を返します
+func (c *attachmentCollector) generatedFile(filePath string, image bool) (string, bool) {
+	if c == nil || filePath == "" || isExternalURL(filePath) {
+		return "", false
+	}
+	return c.add(filePath, image, true), true
+}
+
+// add は添付ファイルを登録し、その名前を返します（同じパスは1つにまとめます）
+func (c *attachmentCollector) add(filePath string, image, generated bool) string {
+	if i, ok := c.seen[filePath]; ok {
+		c.attachments[i].Image = c.attachments[i].Image || image
+		return c.attachments[i].Name
+	}
+
+	name := c.uniqueName(path.Base(filepath.ToSlash(filePath)))
+	c.seen[filePath] = len(c.attachments)
+	c.attachments = append(c.attachments, Attachment{Path: filePath, Name: name, Image: image, Generated: generated})
+	return name
+}
+
+// uniqueName は別のパスの同じ名前のファイルと区別できるよう、2つ目以降の名前に -1, -2 を付けます（flow.png → flow-1.png）
+func (c *attachmentCollector) uniqueName(name string) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for n := 1; c.names[candidate]; n++ {
+		candidate = stem + "-" + strconv.Itoa(n) + ext
+	}
+	c.names[candidate] = true
+	return candidate
+}
+
+// list は収集した添付ファイルを返します
+func (c *attachmentCollector) list() []Attachment {
+	if c == nil {
+		return nil
+	}
+	return c.attachments
+}
+
+// writeAttachmentImage はローカル画像を #image マクロで出力します
+func writeAttachmentImage(w *Writer, name string) {
+	w.WriteString("#image(" + name + ")")
+}
+
+// writeAttachmentLink はローカルファイルへのリンクを #attach マクロで出力します
+func writeAttachmentLink(w *Writer, name string) {
+	w.WriteString("#attach(" + name + ")")
+}
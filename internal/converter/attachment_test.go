@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertWithLocalAttachments(t *testing.T) {
+	input := "![diagram](./img/flow.png)\n\n[spec](./spec.pdf) と [設計](design.md) と [外部](https://example.com/a.pdf)\n\n![再掲](img/flow.png)"
+
+	result, err := ConvertDetailed(input, WithLocalAttachments())
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expectedBody := "#image(flow.png)\n#attach(spec.pdf) と [[設計:design.md]] と [[外部:https://example.com/a.pdf]]\n#image(flow.png)"
+	if result.Body != expectedBody {
+		t.Errorf("期待値: %q, 実際の値: %q", expectedBody, result.Body)
+	}
+
+	expectedAttachments := []Attachment{
+		{Path: "img/flow.png", Name: "flow.png", Image: true},
+		{Path: "spec.pdf", Name: "spec.pdf", Image: false},
+	}
+	if !reflect.DeepEqual(result.Attachments, expectedAttachments) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expectedAttachments, result.Attachments)
+	}
+}
+
+func TestConvertWithLocalAttachmentsSameName(t *testing.T) {
+	input := "![a](img/a/flow.png) ![b](img/b/flow.png) ![c](img/c/flow.png) ![a2](img/a/flow.png) [ログ](flow-1.png)"
+
+	result, err := ConvertDetailed(input, WithLocalAttachments())
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expectedBody := "#image(flow.png) #image(flow-1.png) #image(flow-2.png) #image(flow.png) #attach(flow-1-1.png)"
+	if result.Body != expectedBody {
+		t.Errorf("期待値: %q, 実際の値: %q", expectedBody, result.Body)
+	}
+
+	expectedAttachments := []Attachment{
+		{Path: "img/a/flow.png", Name: "flow.png", Image: true},
+		{Path: "img/b/flow.png", Name: "flow-1.png", Image: true},
+		{Path: "img/c/flow.png", Name: "flow-2.png", Image: true},
+		{Path: "flow-1.png", Name: "flow-1-1.png", Image: false},
+	}
+	if !reflect.DeepEqual(result.Attachments, expectedAttachments) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expectedAttachments, result.Attachments)
+	}
+}
+
+func TestConvertWithoutLocalAttachments(t *testing.T) {
+	result, err := ConvertDetailed("![diagram](./img/flow.png)")
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	if result.Body != "![diagram](./img/flow.png)" {
+		t.Errorf("予期しない本文: %q", result.Body)
+	}
+	if result.Attachments != nil {
+		t.Errorf("添付ファイルは収集されないはずです: %+v", result.Attachments)
+	}
+}
+
+func TestConvertWithLocalAttachmentsMarkdownFormat(t *testing.T) {
+	// Markdown形式ではローカルファイルの参照を書き換えられないため、組み合わせをエラーにする
+	if _, err := ConvertDetailed("![diagram](./img/flow.png)", WithLocalAttachments(), WithFormat(FormatMarkdown)); err == nil {
+		t.Error("期待されたエラーが発生しませんでした")
+	}
+
+	var out strings.Builder
+	if _, err := ConvertTo(&out, strings.NewReader("![diagram](./img/flow.png)"), WithLocalAttachments(), WithFormat(FormatMarkdown)); err == nil {
+		t.Error("期待されたエラーが発生しませんでした")
+	}
+}
@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// DefaultLanguageAliases はコードブロックの言語名の別名とBacklogのハイライトが対応する言語名の対応表です
+// 値が空の場合は言語指定なしで出力します
+var DefaultLanguageAliases = map[string]string{
+	"golang":     "go",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"console":    "bash",
+	"yml":        "yaml",
+	"js":         "javascript",
+	"jsx":        "javascript",
+	"mjs":        "javascript",
+	"ts":         "typescript",
+	"tsx":        "typescript",
+	"py":         "python",
+	"py3":        "python",
+	"python3":    "python",
+	"rb":         "ruby",
+	"c++":        "cpp",
+	"cc":         "cpp",
+	"cs":         "csharp",
+	"c#":         "csharp",
+	"kt":         "kotlin",
+	"rs":         "rust",
+	"md":         "markdown",
+	"ps1":        "powershell",
+	"htm":        "html",
+	"objc":       "objectivec",
+	"text":       "",
+	"txt":        "",
+	"plaintext":  "",
+	"plain":      "",
+	"dockerfile": "docker",
+}
+
+// titleAttribute は情報文字列の title="..." / filename="..." 属性にマッチします（引用符は省略可能）
+var titleAttribute = regexp.MustCompile(`\b(?:title|filename|file)=(?:"([^"]*)"|'([^']*)'|([^\s,}]+))`)
+
+// codeInfo はフェンスコードブロックの情報文字列を解析した結果です
+type codeInfo struct {
+	language string
+	title    string
+}
+
+// parseCodeInfo は情報文字列から言語名とタイトルを取り出します
+// 次の書き方に対応します: go title="main.go" / go{title="main.go"} / {.go title="main.go"} / go:main.go
+func parseCodeInfo(info string) codeInfo {
+	info = strings.TrimSpace(info)
+	var result codeInfo
+
+	if strings.HasPrefix(info, "{") {
+		// Pandoc形式の属性のみの指定は .class を言語名とみなす
+		for _, field := range strings.Fields(strings.Trim(info, "{}")) {
+			if strings.HasPrefix(field, ".") {
+				result.language = field[1:]
+				break
+			}
+		}
+	} else {
+		end := strings.IndexAny(info, " \t{,")
+		if end < 0 {
+			end = len(info)
+		}
+		result.language = info[:end]
+		// Qiita形式の lang:filename
+		if language, title, ok := strings.Cut(result.language, ":"); ok {
+			result.language, result.title = language, title
+		}
+	}
+
+	if m := titleAttribute.FindStringSubmatch(info); m != nil {
+		result.title = m[1] + m[2] + m[3]
+	}
+	return result
+}
+
+// normalizeLanguage は別名の対応表に従って言語名を正規化します（対応表にない言語名はそのまま返します）
+func normalizeLanguage(language string, aliases map[string]string) string {
+	if aliases == nil {
+		aliases = DefaultLanguageAliases
+	}
+	if normalized, ok := aliases[strings.ToLower(language)]; ok {
+		return normalized
+	}
+	return language
+}
+
+// codeBlockInfo はコードブロックの正規化した言語名とタイトルを返します
+func (c *config) codeBlockInfo(node *ast.FencedCodeBlock, source []byte) codeInfo {
+	var info codeInfo
+	if node.Info != nil {
+		info = parseCodeInfo(string(node.Info.Segment.Value(source)))
+	}
+	info.language = normalizeLanguage(info.language, c.languageAliases)
+	return info
+}
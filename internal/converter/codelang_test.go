@@ -0,0 +1,94 @@
+package converter
+
+import "testing"
+
+func TestParseCodeInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     string
+		expected codeInfo
+	}{
+		{name: "言語のみ", info: "go", expected: codeInfo{language: "go"}},
+		{name: "空", info: "", expected: codeInfo{}},
+		{name: "title属性", info: `go title="main.go"`, expected: codeInfo{language: "go", title: "main.go"}},
+		{name: "波括弧の属性", info: `go{title="main.go"}`, expected: codeInfo{language: "go", title: "main.go"}},
+		{name: "カンマ区切りの属性", info: `python,filename=app.py`, expected: codeInfo{language: "python", title: "app.py"}},
+		{name: "Pandoc形式", info: `{.ruby title='app.rb'}`, expected: codeInfo{language: "ruby", title: "app.rb"}},
+		{name: "Qiita形式", info: "ruby:app.rb", expected: codeInfo{language: "ruby", title: "app.rb"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseCodeInfo(tt.info)
+			if result != tt.expected {
+				t.Errorf("期待値: %+v, 実際の値: %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertCodeLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "別名を正規化",
+			input:    "```golang\nfunc main() {}\n```",
+			expected: ">{code:go}\nfunc main() {}\n{/code}<",
+		},
+		{
+			name:     "大文字の別名",
+			input:    "```YML\nkey: value\n```",
+			expected: ">{code:yaml}\nkey: value\n{/code}<",
+		},
+		{
+			name:     "属性を除去",
+			input:    "```go{title=\"main.go\"}\npackage main\n```",
+			expected: ">{code:go}\npackage main\n{/code}<",
+		},
+		{
+			name:     "言語指定なしにする別名",
+			input:    "```text\nplain\n```",
+			expected: ">{code}\nplain\n{/code}<",
+		},
+		{
+			name:     "タイトルを出力",
+			input:    "```sh title=\"setup.sh\"\nmake\n```",
+			opts:     []Option{WithCodeTitle()},
+			expected: "''setup.sh''\n>{code:bash}\nmake\n{/code}<",
+		},
+		{
+			name:     "Markdown出力でタイトルを出力",
+			input:    "```rb:app.rb\nputs 1\n```",
+			opts:     []Option{WithFormat(FormatMarkdown), WithCodeTitle()},
+			expected: "**app.rb**\n```ruby\nputs 1\n```",
+		},
+		{
+			name:     "別名を追加",
+			input:    "```tf\nresource {}\n```\n\n```golang\n```",
+			opts:     []Option{WithLanguageAliases(map[string]string{"TF": "hcl"})},
+			expected: ">{code:hcl}\nresource {}\n{/code}<\n>{code:go}\n{/code}<",
+		},
+		{
+			name:     "既定の別名を上書き",
+			input:    "```sh\nls\n```",
+			opts:     []Option{WithLanguageAliases(map[string]string{"sh": "shell"})},
+			expected: ">{code:shell}\nls\n{/code}<",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
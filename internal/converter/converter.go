@@ -2,296 +2,772 @@ package converter
 
 import (
 	"bytes"
+	"slices"
 	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	gast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
+// Result は変換結果を表します
+type Result struct {
+	// Body はBacklog記法に変換した本文です
+	Body string
+	// Title は課題の件名・Wikiページ名に使う見出しです（WithTitleFromFirstH1指定時のみ）
+	Title string
+	// Attachments は本文から参照されているローカルファイルです（WithLocalAttachments指定時のみ）
+	Attachments []Attachment
+	// Warnings は非対応の記法や情報が失われる変換についての警告です
+	Warnings []Warning
+	// Metadata は文書の見出し・リンク・画像の一覧です（WithTitleFromFirstH1で本文から取り除いた見出しも含みます）
+	Metadata Metadata
+}
+
 // Convert はMarkdownテキストをBacklog記法に変換します
-func Convert(markdown string) (string, error) {
+func Convert(markdown string, opts ...Option) (string, error) {
+	result, err := ConvertDetailed(markdown, opts...)
+	if err != nil {
+		return "", err
+	}
+	return result.Body, nil
+}
+
+// ConvertDetailed はMarkdownテキストをBacklog記法に変換し、本文以外の情報も含めて返します
+func ConvertDetailed(markdown string, opts ...Option) (*Result, error) {
+	cfg := newConfig(opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	if markdown == "" {
-		return "", nil
+		return &Result{}, nil
 	}
+	return convertDocument([]byte(markdown), cfg)
+}
+
+// convertDocument は入力全体を変換します
+// HTMLの場合はMarkdownに変換してから変換します
+func convertDocument(input []byte, cfg *config) (*Result, error) {
+	if cfg.input != InputHTML {
+		return convert(input, cfg, false)
+	}
+
+	result, err := convert(htmlToMarkdown(input), cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	// 警告などの行番号はHTMLから変換したMarkdownの行番号で、入力の行とは対応しないため除く
+	for i := range result.Warnings {
+		result.Warnings[i].Line = 0
+	}
+	result.Metadata.clearLines()
+	return result, nil
+}
 
-	// goldmarkでMarkdownをパース（GFM拡張を有効化）
-	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
-	reader := text.NewReader([]byte(markdown))
+// convert はMarkdownを変換します
+// moreがtrueの場合は後に続くブロックがあるものとして出力します（ConvertToで分割した入力の途中部分）
+func convert(markdown []byte, cfg *config, more bool) (*Result, error) {
+	// goldmarkでMarkdownをパース
+	md := newMarkdown(cfg)
+	reader := text.NewReader(markdown)
 	document := md.Parser().Parse(reader)
 
-	// ASTをウォークしてBacklog記法に変換
-	var buffer bytes.Buffer
 	source := reader.Source()
+	diag := &diagnostics{source: source}
 
-	err := ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		switch node := n.(type) {
-		case *ast.Heading:
-			if entering {
-				writeHeading(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+	// 見出しの一覧に件名も含めるため、件名を取り除く前に集める
+	metadata, err := collectMetadata(document, source, cfg, diag)
+	if err != nil {
+		return nil, err
+	}
 
-		case *ast.Emphasis:
-			if entering {
-				writeEmphasis(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+	// 最初のH1は件名として本文から取り除く
+	var title string
+	if cfg.titleFromFirstH1 {
+		title = promoteTitle(document, source)
+	}
 
-		case *gast.Strikethrough:
-			if entering {
-				writeStrikethrough(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+	// 出力形式に応じた組み込みのNodeRendererに、利用者のNodeRendererを優先度順に重ねる
+	var builtin NodeRenderer
+	var backlog *backlogRenderer
+	if cfg.format == FormatMarkdown {
+		builtin = &markdownRenderer{cfg: cfg}
+	} else {
+		backlog = newBacklogRenderer(cfg)
+		backlog.more = more
+		builtin = backlog
+	}
+	renderers := append(util.PrioritizedSlice{util.Prioritized(builtin, builtinRendererPriority)}, cfg.nodeRenderers...)
 
-		case *ast.List:
-			// リストは子要素（ListItem）の処理に任せる
-			// 何もしない
+	// ASTをウォークして変換
+	registry := newNodeRendererRegistry(renderers)
+	if backlog != nil {
+		backlog.registry = registry
+	}
+	w := newWriter(diag)
+	if err := registry.render(w, source, document); err != nil {
+		return nil, err
+	}
 
-		case *ast.ListItem:
-			if entering {
-				writeListItem(&buffer, node, source)
-				// ネストリストを含む可能性があるので、子要素も処理
-				return ast.WalkContinue, nil
-			}
+	if backlog == nil {
+		return &Result{Body: strings.TrimRight(w.String(), "\n"), Title: title, Warnings: diag.warnings, Metadata: *metadata}, nil
+	}
 
-		case *ast.Link:
-			if entering {
-				writeLink(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+	result := w.String()
+	// 末尾の不要な改行を除去（取り除いたHTMLやリンク参照定義など何も出力しないブロックが文書の最後にあると、
+	// 直前のブロックの後に改行が付くため、すべて除く）
+	if !more {
+		result = strings.TrimRight(result, "\n")
+	}
 
-		case *ast.CodeSpan:
-			if entering {
-				writeCodeSpan(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+	// 目次マーカーを生成した目次に置き換え
+	if cfg.tocStyle != TOCNone {
+		result = strings.ReplaceAll(result, tocPlaceholder, renderTOC(cfg.tocStyle, backlog.headings))
+	}
 
-		case *ast.FencedCodeBlock:
-			if entering {
-				writeFencedCodeBlock(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+	return &Result{Body: result, Title: title, Attachments: backlog.attachments.list(), Warnings: diag.warnings, Metadata: *metadata}, nil
+}
 
-		case *ast.Blockquote:
-			if entering {
-				writeBlockquote(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+// backlogRenderer はASTをBacklog記法で出力する組み込みのNodeRendererです
+type backlogRenderer struct {
+	cfg         *config
+	headings    []tocEntry
+	attachments *attachmentCollector
+	// footnotes は番号と脚注の対応です（脚注を括弧書きで展開する場合のみ）
+	footnotes map[int]*gast.Footnote
+	// listDepth は処理中のリストアイテムのネストの深さです
+	// 祖先ノードを辿らずにリスト内かどうかを判定するためにウォーク中に増減させます
+	listDepth int
+	// registry は見出しや表のセルなどのインライン要素を出力するためのレジストリです（convertで設定します）
+	registry *nodeRendererRegistry
+	// inlineDepth はinlineContentで子要素を出力している最中かどうか（入れ子の深さ）です
+	inlineDepth int
+	// more はConvertToで分割した入力の途中部分を変換しているかどうかです（文書の後にもブロックが続きます）
+	more bool
+}
 
-		case *gast.Table:
-			if entering {
-				writeTable(&buffer, node, source)
-				return ast.WalkSkipChildren, nil
-			}
+// newBacklogRenderer は新しいbacklogRendererを生成します
+func newBacklogRenderer(cfg *config) *backlogRenderer {
+	r := &backlogRenderer{cfg: cfg}
+	if cfg.localAttachments {
+		r.attachments = newAttachmentCollector()
+	}
+	return r
+}
 
-		case *ast.Text:
-			if entering && !isChildOfHeading(node) && !isChildOfEmphasis(node) && !isChildOfStrikethrough(node) && !isChildOfListItem(node) && !isChildOfLink(node) && !isChildOfCodeSpan(node) && !isChildOfFencedCodeBlock(node) && !isChildOfBlockquote(node) && !isChildOfTable(node) {
-				writeText(&buffer, node, source)
-			}
+// hasNextBlock はノードの後に続くブロックがあるかどうかを判定します
+// Backlog記法ではブロックの後の改行がこれで変わるため、分割した入力の途中部分では文書の最後のブロックにも後続があるものとして扱います
+func (r *backlogRenderer) hasNextBlock(node ast.Node) bool {
+	if node.NextSibling() != nil {
+		return true
+	}
+	return r.more && node.Parent() != nil && node.Parent().Kind() == ast.KindDocument
+}
 
-		case *ast.Paragraph:
-			if !entering && node.NextSibling() != nil && !isNextSiblingList(node) && !isChildOfBlockquote(node) && !isChildOfListItem(node) {
-				buffer.WriteString("\n")
-			}
+// RegisterFuncs はBacklog記法の出力関数を登録します
+func (r *backlogRenderer) RegisterFuncs(reg NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, r.renderHeading)
+	reg.Register(ast.KindEmphasis, r.renderEmphasis)
+	reg.Register(gast.KindStrikethrough, r.renderStrikethrough)
+	reg.Register(ast.KindListItem, r.renderListItem)
+	reg.Register(ast.KindLink, r.renderLink)
+	reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+	reg.Register(ast.KindBlockquote, r.renderBlockquote)
+	reg.Register(gast.KindTable, r.renderTable)
+	reg.Register(KindMention, r.renderMention)
+	reg.Register(KindEmoji, r.renderEmoji)
+	reg.Register(ast.KindText, r.renderText)
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindThematicBreak, r.renderThematicBreak)
+	reg.Register(gast.KindTaskCheckBox, r.renderTaskCheckBox)
+	reg.Register(ast.KindParagraph, r.renderParagraph)
+	reg.Register(ast.KindTextBlock, r.renderTextBlock)
+	reg.Register(ast.KindString, r.renderString)
+	reg.Register(ast.KindAutoLink, r.renderLink)
+	reg.Register(gast.KindDefinitionTerm, r.renderDefinitionTerm)
+	reg.Register(gast.KindDefinitionDescription, r.renderDefinitionDescription)
+	reg.Register(ast.KindDocument, r.renderDocument)
+	reg.Register(gast.KindFootnoteLink, r.renderFootnoteLink)
+	reg.Register(gast.KindFootnoteList, r.renderFootnoteList)
+	reg.Register(gast.KindFootnote, r.renderFootnote)
+	reg.Register(gast.KindFootnoteBacklink, r.renderFootnoteBacklink)
+}
+
+func (r *backlogRenderer) renderDocument(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && r.cfg.footnotes == FootnoteInline {
+		r.footnotes = detachFootnotes(n)
+	}
+	return ast.WalkContinue, nil
+}
+
+// inlineContent はノードの子要素（インライン要素）を登録された出力関数で出力し、その内容を返します
+// 利用者のNodeRendererも使われるため、見出しや表のセルの中のメンション・絵文字・脚注なども失われません
+// ソフト改行・ハード改行は "\n" になります
+func (r *backlogRenderer) inlineContent(w *Writer, source []byte, node ast.Node) (string, error) {
+	inline := newWriter(w.diag)
+	r.inlineDepth++
+	defer func() { r.inlineDepth-- }()
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if err := r.registry.render(inline, source, child); err != nil {
+			return "", err
+		}
+	}
+	return inline.String(), nil
+}
+
+// inlineLine はinlineContentの内容を改行を除いた1行で返します
+func (r *backlogRenderer) inlineLine(w *Writer, source []byte, node ast.Node) (string, error) {
+	content, err := r.inlineContent(w, source, node)
+	return strings.ReplaceAll(content, "\n", ""), err
+}
+
+func (r *backlogRenderer) renderHeading(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*ast.Heading)
+		level := shiftHeadingLevel(node.Level, r.cfg)
+		text, err := r.inlineLine(w, source, node)
+		if err != nil {
+			return ast.WalkStop, err
 		}
+		writeHeading(w, text, level)
+		r.headings = append(r.headings, tocEntry{level: level, text: headingText(node, source)})
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
 
+func (r *backlogRenderer) renderEmphasis(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		text, err := r.inlineLine(w, source, n)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+		writeEmphasis(w, n.(*ast.Emphasis), text)
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderStrikethrough(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		text, err := r.inlineLine(w, source, n)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+		w.WriteString("%%" + text + "%%")
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderListItem(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		r.listDepth--
 		return ast.WalkContinue, nil
-	})
+	}
 
+	node := n.(*ast.ListItem)
+	r.listDepth++
+	ordered := isInOrderedList(node)
+	if ordered && r.listDepth > 1 {
+		w.Warn(node, "nested numbered list was flattened")
+	}
+	text, err := r.listItemText(w, source, node)
 	if err != nil {
-		return "", err
+		return ast.WalkStop, err
 	}
+	writeListItem(w, text, r.listDepth, ordered)
+	// ネストリストを含む可能性があるので、子要素も処理（段落はrenderParagraph・renderTextBlockで読み飛ばす）
+	return ast.WalkContinue, nil
+}
 
-	result := buffer.String()
-	// 末尾の不要な改行を除去
-	result = strings.TrimSuffix(result, "\n")
+// listItemText はリストアイテムの最初の段落の内容を1行で返します
+// Backlog記法のリストアイテムは段落を区切れないため、すぐ後に続く段落は空白でつないで警告します
+func (r *backlogRenderer) listItemText(w *Writer, source []byte, listItem *ast.ListItem) (string, error) {
+	var texts []string
+	for _, paragraph := range listItemParagraphs(listItem) {
+		text, err := r.inlineLine(w, source, paragraph)
+		if err != nil {
+			return "", err
+		}
+		if text == "" && len(texts) > 0 {
+			continue
+		}
+		if len(texts) > 0 {
+			w.Warn(paragraph, "paragraph break in a list item was replaced with a space")
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, " "), nil
+}
 
-	return result, nil
+// listItemParagraphs はリストアイテムの最初の段落と、そのすぐ後に続く段落を返します
+func listItemParagraphs(listItem ast.Node) []ast.Node {
+	var paragraphs []ast.Node
+	for child := listItem.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+			paragraphs = append(paragraphs, child)
+			continue
+		}
+		if len(paragraphs) > 0 {
+			break
+		}
+	}
+	return paragraphs
 }
 
-// writeHeading は見出しノードをBacklog記法で出力します
-func writeHeading(buffer *bytes.Buffer, heading *ast.Heading, source []byte) {
-	level := heading.Level
-	prefix := strings.Repeat("*", level)
-	buffer.WriteString(prefix + " ")
+// isListItemText はノードがlistItemTextで出力するリストアイテムの段落かどうかを判定します
+func isListItemText(node ast.Node) bool {
+	if _, ok := node.Parent().(*ast.ListItem); !ok {
+		return false
+	}
+	return slices.Contains(listItemParagraphs(node.Parent()), node)
+}
 
-	// 見出しのテキスト内容を取得
-	for child := heading.FirstChild(); child != nil; child = child.NextSibling() {
-		if textNode, ok := child.(*ast.Text); ok {
-			buffer.Write(textNode.Segment.Value(source))
+func (r *backlogRenderer) renderLink(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		spec := newLinkSpec(n, source)
+		spec.text = string(unescapeMarkdown([]byte(spec.text)))
+		if spec.auto && r.cfg.autoLinkStyle == AutoLinkBare && spec.isBareURL() {
+			// BacklogはURLを自動でリンクにするためそのまま出力
+			w.WriteString(spec.text)
+		} else if name, ok := r.attachments.localFile(spec.destination, false); ok {
+			writeAttachmentLink(w, name)
+		} else {
+			writeLink(w, spec, r.cfg.linkResolver)
 		}
+		return ast.WalkSkipChildren, nil
 	}
-	buffer.WriteString("\n")
+	return ast.WalkContinue, nil
 }
 
-// writeText はテキストノードを出力します（改行も含めて）
-func writeText(buffer *bytes.Buffer, textNode *ast.Text, source []byte) {
-	segment := textNode.Segment
-	buffer.Write(segment.Value(source))
+func (r *backlogRenderer) renderImage(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*ast.Image)
+		if name, ok := r.attachments.localFile(string(node.Destination), true); ok {
+			writeAttachmentImage(w, name)
+		} else {
+			writeImage(w, node, source, r.cfg.linkResolver)
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
 
-	// セグメント後に改行があるかチェック
-	if segment.Stop < len(source) && source[segment.Stop] == '\n' {
-		buffer.WriteString("\n")
+func (r *backlogRenderer) renderCodeSpan(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeCodeSpan(w, n.(*ast.CodeSpan), source)
+		return ast.WalkSkipChildren, nil
 	}
+	return ast.WalkContinue, nil
 }
 
-// writeEmphasis は太字・斜体ノードをBacklog記法で出力します
-func writeEmphasis(buffer *bytes.Buffer, emphasis *ast.Emphasis, source []byte) {
-	switch emphasis.Level {
-	case 2:
-		// 太字の場合
-		buffer.WriteString("''")
-		// 太字内のテキスト内容を取得
-		for child := emphasis.FirstChild(); child != nil; child = child.NextSibling() {
-			if textNode, ok := child.(*ast.Text); ok {
-				buffer.Write(textNode.Segment.Value(source))
+func (r *backlogRenderer) renderFencedCodeBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*ast.FencedCodeBlock)
+		if output := r.cfg.codeBlockHook.handle(w, node, source); output != nil {
+			r.writeCodeBlockOutput(w, node, source, output)
+		} else {
+			info := r.cfg.codeBlockInfo(node, source)
+			if r.cfg.codeTitle && info.title != "" {
+				w.WriteString("''" + info.title + "''\n")
 			}
+			writeFencedCodeBlock(w, node, info.language, source)
 		}
-		buffer.WriteString("''")
-	case 1:
-		// 斜体の場合
-		buffer.WriteString("'''")
-		// 斜体内のテキスト内容を取得
-		for child := emphasis.FirstChild(); child != nil; child = child.NextSibling() {
-			if textNode, ok := child.(*ast.Text); ok {
-				buffer.Write(textNode.Segment.Value(source))
-			}
+		if r.hasNextBlock(node) {
+			w.WriteString("\n")
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+// writeCodeBlockOutput はCodeBlockHookの変換結果を出力します
+func (r *backlogRenderer) writeCodeBlockOutput(w *Writer, node *ast.FencedCodeBlock, source []byte, output *CodeBlockOutput) {
+	if output.Caption != "" {
+		w.WriteString("''" + output.Caption + "''\n")
+	}
+	if output.Image == "" {
+		writeFencedCodeBlock(w, node, r.cfg.codeBlockInfo(node, source).language, source)
+		return
+	}
+
+	if name, ok := r.attachments.generatedFile(output.Image, true); ok {
+		writeAttachmentImage(w, name)
+	} else {
+		alt := output.Caption
+		if alt == "" {
+			alt = string(node.Language(source))
+		}
+		destination := output.Image
+		if target := resolveLink(r.cfg.linkResolver, destination); target.URL != "" {
+			destination = target.URL
 		}
-		buffer.WriteString("'''")
+		w.WriteString("![" + alt + "](" + destination + ")")
 	}
 }
 
-// isChildOfHeading はノードが見出しの子要素かどうかを判定します
-func isChildOfHeading(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.Heading); ok {
-			return true
+func (r *backlogRenderer) renderBlockquote(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		if err := r.writeBlockquote(w, n.(*ast.Blockquote), source); err != nil {
+			return ast.WalkStop, err
 		}
-		parent = parent.Parent()
+		return ast.WalkSkipChildren, nil
 	}
-	return false
+	return ast.WalkContinue, nil
 }
 
-// isChildOfEmphasis はノードが太字/斜体の子要素かどうかを判定します
-func isChildOfEmphasis(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.Emphasis); ok {
-			return true
+func (r *backlogRenderer) renderTable(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		if err := r.writeTable(w, n.(*gast.Table), source); err != nil {
+			return ast.WalkStop, err
 		}
-		parent = parent.Parent()
+		return ast.WalkSkipChildren, nil
 	}
-	return false
+	return ast.WalkContinue, nil
 }
 
-// writeStrikethrough は打ち消し線ノードをBacklog記法で出力します
-func writeStrikethrough(buffer *bytes.Buffer, strikethrough ast.Node, source []byte) {
-	buffer.WriteString("%%")
-	// 打ち消し線内のテキスト内容を取得
-	for child := strikethrough.FirstChild(); child != nil; child = child.NextSibling() {
-		if textNode, ok := child.(*ast.Text); ok {
-			buffer.Write(textNode.Segment.Value(source))
+func (r *backlogRenderer) renderMention(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeMention(w, n.(*Mention), r.cfg.users)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderEmoji(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeEmoji(w, n.(*Emoji))
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderText は他の出力関数が処理しなかったテキストを出力します
+// 見出しやリンクなどの出力関数は子要素をまとめて出力してスキップするため、ここに来るのはそれ以外のテキストだけです
+// リストアイテムは最初の段落を自身で出力したうえで子要素を処理するため、リスト内のテキストは出力しません
+func (r *backlogRenderer) renderText(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*ast.Text)
+	if r.inlineDepth > 0 {
+		w.Write(unescapeMarkdown(node.Segment.Value(source)))
+		if node.SoftLineBreak() || node.HardLineBreak() {
+			w.WriteString("\n")
 		}
+	} else if r.listDepth == 0 {
+		writeText(w, node, source)
 	}
-	buffer.WriteString("%%")
+	return ast.WalkContinue, nil
 }
 
-// isChildOfStrikethrough はノードが打ち消し線の子要素かどうかを判定します
-func isChildOfStrikethrough(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*gast.Strikethrough); ok {
-			return true
+func (r *backlogRenderer) renderHTMLBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*ast.HTMLBlock)
+	if !isTOCHTMLBlock(node, source) {
+		w.Warn(node, "raw HTML is not supported and was removed")
+		return ast.WalkContinue, nil
+	}
+	if r.cfg.tocStyle != TOCNone {
+		w.WriteString(tocPlaceholder)
+		if node.NextSibling() != nil {
+			w.WriteString("\n")
 		}
-		parent = parent.Parent()
+		return ast.WalkSkipChildren, nil
 	}
-	return false
+	return ast.WalkContinue, nil
 }
 
-// writeListItem はリストアイテムノードをBacklog記法で出力します
-func writeListItem(buffer *bytes.Buffer, listItem *ast.ListItem, source []byte) {
-	// ネストレベルを計算
-	nestLevel := calculateListNestLevel(listItem)
+func (r *backlogRenderer) renderRawHTML(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.Warn(n, "inline HTML is not supported and was removed")
+	}
+	return ast.WalkContinue, nil
+}
 
-	// 親リストが番号付きリストかどうかを判定
-	isOrderedList := isInOrderedList(listItem)
+func (r *backlogRenderer) renderCodeBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.Warn(n, "indented code block is not supported and was removed; use a fenced code block")
+	}
+	return ast.WalkContinue, nil
+}
 
-	// プレフィックスを生成
-	var prefix string
-	if isOrderedList {
-		// 番号付きリストの場合は「+」を使用（ネスト関係なく平坦化）
-		prefix = "+"
+func (r *backlogRenderer) renderThematicBreak(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.Warn(n, "thematic break is not supported and was removed")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderTaskCheckBox(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.Warn(n, "task list checkbox is not supported and was removed")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderParagraph(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	node := n.(*ast.Paragraph)
+	if entering && r.listDepth > 0 && r.inlineDepth == 0 {
+		// リストアイテムの段落はrenderListItemが出力済み（ネストしたリストなどの後の段落は出力しない）
+		if !isListItemText(node) {
+			w.Warn(node, "paragraph after the first in a list item is not supported and was removed")
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	// [TOC] だけのトップレベルのパラグラフを目次の目印とする（リストや引用の中は本文のまま）
+	if entering && r.cfg.tocStyle != TOCNone && node.Parent().Kind() == ast.KindDocument && isTOCParagraph(node, source) {
+		// 目次は全見出しの走査後に生成するため目印を出力しておく
+		w.WriteString(tocPlaceholder)
+		return ast.WalkSkipChildren, nil
+	}
+	if !entering && r.hasNextBlock(node) && !isNextSiblingList(node) && r.listDepth == 0 {
+		w.WriteString("\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderTextBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && r.listDepth > 0 && r.inlineDepth == 0 {
+		// リストアイテムの段落はrenderListItemが出力済み
+		if !isListItemText(n) && n.HasChildren() {
+			w.Warn(n, "paragraph after the first in a list item is not supported and was removed")
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderString(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && (r.inlineDepth > 0 || r.listDepth == 0) {
+		w.Write(n.(*ast.String).Value)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderDefinitionTerm(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.endLine()
+		w.WriteString("''" + string(unescapeMarkdown([]byte(plainText(n, source)))) + "''\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *backlogRenderer) renderDefinitionDescription(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.pushPrefix(definitionIndent, definitionIndent)
 	} else {
-		// 通常のリストの場合はネストレベルに応じて「-」を繰り返し
-		prefix = strings.Repeat("-", nestLevel)
+		w.endLine()
+		w.popPrefix()
 	}
-	buffer.WriteString(prefix + " ")
+	return ast.WalkContinue, nil
+}
 
-	// リストアイテムの最初のテキスト内容のみを取得（ネストは別処理）
-	for child := listItem.FirstChild(); child != nil; child = child.NextSibling() {
-		switch childNode := child.(type) {
-		case *ast.Paragraph:
-			// Paragraphの子要素（Text）を処理
-			for grandChild := childNode.FirstChild(); grandChild != nil; grandChild = grandChild.NextSibling() {
-				if textNode, ok := grandChild.(*ast.Text); ok {
-					buffer.Write(textNode.Segment.Value(source))
-				}
+func (r *backlogRenderer) renderFootnoteLink(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*gast.FootnoteLink)
+		if r.cfg.footnotes == FootnoteInline {
+			if err := r.writeFootnoteContent(w, source, node.Index); err != nil {
+				return ast.WalkStop, err
 			}
-			// 最初のParagraphのみ処理して終了
-			buffer.WriteString("\n")
-			return
-		case *ast.TextBlock:
-			// TextBlockの子要素（Text）を処理
-			for grandChild := childNode.FirstChild(); grandChild != nil; grandChild = grandChild.NextSibling() {
-				if textNode, ok := grandChild.(*ast.Text); ok {
-					buffer.Write(textNode.Segment.Value(source))
-				}
-			}
-			// 最初のTextBlockのみ処理して終了
-			buffer.WriteString("\n")
-			return
+		} else {
+			w.WriteString(footnoteMarker(node.Index))
 		}
 	}
-	buffer.WriteString("\n")
+	return ast.WalkContinue, nil
 }
 
-// calculateListNestLevel はリストアイテムのネストレベルを計算します
-func calculateListNestLevel(listItem *ast.ListItem) int {
-	level := 1
-	parent := listItem.Parent()
-
-	for parent != nil {
-		// 親がListの場合、さらにその親のListItemを探す
-		if _, ok := parent.(*ast.List); ok {
-			grandParent := parent.Parent()
-			if _, ok := grandParent.(*ast.ListItem); ok {
-				level++
-				parent = grandParent.Parent()
-			} else {
-				break
+// writeFootnoteContent は脚注の内容を登録された出力関数で出力し、1行の括弧書きにして出力します
+// 脚注の中で同じ脚注を参照していても展開が終わるよう、展開中の脚注は対応から外しておきます
+func (r *backlogRenderer) writeFootnoteContent(w *Writer, source []byte, index int) error {
+	footnote := r.footnotes[index]
+	if footnote == nil {
+		return nil
+	}
+	delete(r.footnotes, index)
+	defer func() { r.footnotes[index] = footnote }()
+
+	var parts []string
+	for child := footnote.FirstChild(); child != nil; child = child.NextSibling() {
+		var content string
+		if _, ok := child.(*ast.Paragraph); ok {
+			var err error
+			if content, err = r.inlineContent(w, source, child); err != nil {
+				return err
 			}
 		} else {
-			parent = parent.Parent()
+			content = plainText(child, source)
+		}
+		if content = strings.TrimSpace(strings.ReplaceAll(content, "\n", " ")); content != "" {
+			parts = append(parts, content)
+		}
+	}
+	w.WriteString(" (" + strings.Join(parts, " ") + ")")
+	return nil
+}
+
+func (r *backlogRenderer) renderFootnoteList(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.blankLine()
+		w.WriteString("''" + footnoteSectionTitle + "''\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderFootnote(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString(footnoteMarker(n.(*gast.Footnote).Index) + " ")
+	} else if n.NextSibling() != nil {
+		w.endLine()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *backlogRenderer) renderFootnoteBacklink(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	// 脚注から参照箇所へ戻るリンクはBacklog記法では表現できないため出力しない
+	return ast.WalkSkipChildren, nil
+}
+
+// newMarkdown は設定に応じたgoldmarkのインスタンスを生成します（テーブル・打ち消し線・タスクリストは常に有効）
+func newMarkdown(cfg *config) goldmark.Markdown {
+	var inlineParsers []util.PrioritizedValue
+	if cfg.users != nil {
+		inlineParsers = append(inlineParsers, util.Prioritized(&mentionParser{}, 500))
+	}
+	if cfg.emoji != nil {
+		inlineParsers = append(inlineParsers, util.Prioritized(&emojiParser{emoji: cfg.emoji}, 500))
+	}
+
+	// GFMのうちLinkifyは本文中のURLの扱いが変わるため指定時のみ有効にする
+	extensions := []goldmark.Extender{extension.Table, extension.Strikethrough, extension.TaskList}
+	if cfg.linkify {
+		extensions = append(extensions, extension.Linkify)
+	}
+	if cfg.footnotes != FootnoteNone {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if cfg.definitionList {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if cfg.typographer {
+		extensions = append(extensions, extension.NewTypographer(extension.WithTypographicSubstitutions(typographicSubstitutions)))
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(append(extensions, cfg.extensions...)...),
+		goldmark.WithParserOptions(parser.WithInlineParsers(inlineParsers...)),
+	)
+}
+
+// writeHeading は出力済みの見出しの内容を指定レベルのBacklog記法で出力します
+func writeHeading(w *Writer, text string, level int) {
+	prefix := strings.Repeat("*", level)
+	w.WriteString(prefix + " ")
+	w.WriteString(text)
+	w.WriteString("\n")
+}
+
+// promoteTitle は最初のH1を文書から取り除き、そのテキストを返します
+func promoteTitle(document ast.Node, source []byte) string {
+	for child := document.FirstChild(); child != nil; child = child.NextSibling() {
+		if heading, ok := child.(*ast.Heading); ok && heading.Level == 1 {
+			document.RemoveChild(document, heading)
+			return headingText(heading, source)
 		}
 	}
+	return ""
+}
 
+// headingText は見出しのテキスト内容（記法を含まない件名・目次用のテキスト）を取得します
+func headingText(heading *ast.Heading, source []byte) string {
+	return string(unescapeMarkdown([]byte(plainText(heading, source))))
+}
+
+// shiftHeadingLevel は設定に従って見出しレベルをずらし、Backlogの対応範囲に収めます
+func shiftHeadingLevel(level int, cfg *config) int {
+	level += cfg.headingOffset
+	if level < 1 {
+		level = 1
+	}
+	if level > cfg.maxHeadingLevel {
+		level = cfg.maxHeadingLevel
+	}
 	return level
 }
 
-// isChildOfListItem はノードがリストアイテムの子要素かどうかを判定します
-func isChildOfListItem(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.ListItem); ok {
-			return true
+// writeText はテキストノードを出力します（改行も含めて）
+func writeText(w *Writer, textNode *ast.Text, source []byte) {
+	segment := textNode.Segment
+	w.Write(unescapeMarkdown(segment.Value(source)))
+
+	// セグメント後に改行があるかチェック
+	if segment.Stop < len(source) && source[segment.Stop] == '\n' {
+		w.WriteString("\n")
+	}
+}
+
+// unescapeMarkdown はMarkdownのバックスラッシュによるエスケープを取り除きます（Backlog記法にはエスケープがないため）
+// 表のセルの区切りにならないよう、\| はそのまま残します
+func unescapeMarkdown(value []byte) []byte {
+	if bytes.IndexByte(value, '\\') < 0 {
+		return value
+	}
+	text := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && value[i+1] != '|' && strings.IndexByte(markdownPunctuation, value[i+1]) >= 0 {
+			i++
 		}
-		parent = parent.Parent()
+		text = append(text, value[i])
 	}
-	return false
+	return text
+}
+
+// inlineText はテキストノード（Typographerが置き換えた記号のStringノードを含む）の内容を返します
+func inlineText(node ast.Node, source []byte) ([]byte, bool) {
+	switch n := node.(type) {
+	case *ast.Text:
+		return n.Segment.Value(source), true
+	case *ast.String:
+		return n.Value, true
+	}
+	return nil, false
+}
+
+// writeEmphasis は出力済みの内容を太字・斜体のBacklog記法で囲んで出力します
+func writeEmphasis(w *Writer, emphasis *ast.Emphasis, text string) {
+	switch emphasis.Level {
+	case 2:
+		// 太字の場合
+		w.WriteString("''" + text + "''")
+	case 1:
+		// 斜体の場合
+		w.WriteString("'''" + text + "'''")
+	}
+}
+
+// writeListItem はリストアイテムの行をBacklog記法で出力します
+// textは最初の段落の内容、nestLevelはリストのネストの深さ（1始まり）、isOrderedListは親リストが番号付きリストかどうかです
+func writeListItem(w *Writer, text string, nestLevel int, isOrderedList bool) {
+	// プレフィックスを生成
+	var prefix string
+	if isOrderedList {
+		// 番号付きリストの場合は「+」を使用（ネスト関係なく平坦化）
+		prefix = "+"
+	} else {
+		// 通常のリストの場合はネストレベルに応じて「-」を繰り返し
+		prefix = strings.Repeat("-", nestLevel)
+	}
+	w.WriteString(prefix + " " + text + "\n")
 }
 
 // isNextSiblingList は次の兄弟ノードがListかどうかを判定します
@@ -303,256 +779,212 @@ func isNextSiblingList(node ast.Node) bool {
 
 // isInOrderedList はリストアイテムが番号付きリストの中にあるかどうかを判定します
 func isInOrderedList(listItem *ast.ListItem) bool {
-	parent := listItem.Parent()
-	for parent != nil {
-		if list, ok := parent.(*ast.List); ok {
-			return list.IsOrdered()
-		}
-		parent = parent.Parent()
-	}
-	return false
+	list, ok := listItem.Parent().(*ast.List)
+	return ok && list.IsOrdered()
 }
 
-// writeLink はリンクノードをBacklog記法で出力します
-func writeLink(buffer *bytes.Buffer, link *ast.Link, source []byte) {
-	buffer.WriteString("[[")
+// writeLink はリンクをBacklog記法で出力します
+// Backlog記法のリンクにはタイトルがないため、タイトルはリンクの後ろに括弧書きで出力します
+func writeLink(w *Writer, spec linkSpec, resolver LinkResolver) {
+	linkText := spec.text
+	if linkText == "" {
+		linkText = spec.title
+	}
 
-	// リンクテキストを取得
-	for child := link.FirstChild(); child != nil; child = child.NextSibling() {
-		if textNode, ok := child.(*ast.Text); ok {
-			buffer.Write(textNode.Segment.Value(source))
+	target := resolveLink(resolver, spec.destination)
+	if target.WikiPage != "" {
+		// Wikiページへのリンク
+		w.WriteString("[[")
+		if linkText != "" && linkText != target.WikiPage {
+			w.WriteString(linkText + ">")
 		}
+		w.WriteString(target.WikiPage + "]]")
+	} else {
+		if linkText == "" {
+			linkText = target.URL
+		}
+		w.WriteString("[[" + linkText + ":" + target.URL + "]]")
 	}
 
-	buffer.WriteString(":")
-	buffer.Write(link.Destination)
-	buffer.WriteString("]]")
+	if spec.title != "" && spec.title != linkText {
+		w.WriteString(" (" + spec.title + ")")
+	}
 }
 
-// isChildOfLink はノードがリンクの子要素かどうかを判定します
-func isChildOfLink(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.Link); ok {
-			return true
+// writeImage は画像ノードを出力します（リンク先のみ解決し、記法はそのまま）
+func writeImage(w *Writer, image *ast.Image, source []byte, resolver LinkResolver) {
+	w.WriteString("![")
+	for child := image.FirstChild(); child != nil; child = child.NextSibling() {
+		if value, ok := inlineText(child, source); ok {
+			w.Write(value)
 		}
-		parent = parent.Parent()
 	}
-	return false
+
+	destination := string(image.Destination)
+	if target := resolveLink(resolver, destination); target.URL != "" {
+		destination = target.URL
+	}
+	w.WriteString("](" + destination + ")")
 }
 
 // writeCodeSpan はインラインコードノードをBacklog記法で出力します
-func writeCodeSpan(buffer *bytes.Buffer, codeSpan *ast.CodeSpan, source []byte) {
-	buffer.WriteString("{code}")
+func writeCodeSpan(w *Writer, codeSpan *ast.CodeSpan, source []byte) {
+	w.WriteString("{code}")
 	// インラインコード内のテキスト内容を取得
 	for child := codeSpan.FirstChild(); child != nil; child = child.NextSibling() {
-		if textNode, ok := child.(*ast.Text); ok {
-			buffer.Write(textNode.Segment.Value(source))
+		if value, ok := inlineText(child, source); ok {
+			w.Write(value)
 		}
 	}
-	buffer.WriteString("{/code}")
-}
-
-// isChildOfCodeSpan はノードがインラインコードの子要素かどうかを判定します
-func isChildOfCodeSpan(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.CodeSpan); ok {
-			return true
-		}
-		parent = parent.Parent()
-	}
-	return false
+	w.WriteString("{/code}")
 }
 
 // writeFencedCodeBlock はコードブロックノードをBacklog記法で出力します
-func writeFencedCodeBlock(buffer *bytes.Buffer, codeBlock *ast.FencedCodeBlock, source []byte) {
+func writeFencedCodeBlock(w *Writer, codeBlock *ast.FencedCodeBlock, lang string, source []byte) {
 	// 開始タグ
-	buffer.WriteString(">{code")
+	w.WriteString(">{code")
 
 	// 言語指定がある場合
-	if codeBlock.Language(source) != nil {
-		lang := string(codeBlock.Language(source))
-		if lang != "" {
-			buffer.WriteString(":" + lang)
-		}
+	if lang != "" {
+		w.WriteString(":" + lang)
 	}
-	buffer.WriteString("}\n")
+	w.WriteString("}\n")
 
 	// コードブロックの内容を出力
 	for i := 0; i < codeBlock.Lines().Len(); i++ {
 		line := codeBlock.Lines().At(i)
-		buffer.Write(line.Value(source))
+		w.Write(line.Value(source))
 	}
 
 	// 終了タグ
-	buffer.WriteString("{/code}<")
-
-	// 次の兄弟ノードがある場合は改行を追加
-	if codeBlock.NextSibling() != nil {
-		buffer.WriteString("\n")
-	}
-}
-
-// isChildOfFencedCodeBlock はノードがコードブロックの子要素かどうかを判定します
-func isChildOfFencedCodeBlock(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.FencedCodeBlock); ok {
-			return true
-		}
-		parent = parent.Parent()
-	}
-	return false
+	w.WriteString("{/code}<")
 }
 
 // writeBlockquote は引用ノードをBacklog記法で出力します
-func writeBlockquote(buffer *bytes.Buffer, blockquote *ast.Blockquote, source []byte) {
+func (r *backlogRenderer) writeBlockquote(w *Writer, blockquote *ast.Blockquote, source []byte) error {
+	isFirstLine := true
 	for child := blockquote.FirstChild(); child != nil; child = child.NextSibling() {
 		if paragraph, ok := child.(*ast.Paragraph); ok {
-			// パラグラフ内の各Textノードを別々の行として処理
-			isFirstText := true
-			for textChild := paragraph.FirstChild(); textChild != nil; textChild = textChild.NextSibling() {
-				if textNode, ok := textChild.(*ast.Text); ok {
-					if !isFirstText {
-						buffer.WriteString("\n")
-					}
-
-					text := string(textNode.Segment.Value(source))
-					buffer.WriteString("> " + text)
-
-					isFirstText = false
+			// パラグラフ内の各行を別々の引用行として処理
+			content, err := r.inlineContent(w, source, paragraph)
+			if err != nil {
+				return err
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+				if !isFirstLine {
+					w.WriteString("\n")
 				}
+				w.WriteString("> " + line)
+				isFirstLine = false
 			}
 		} else if nestedBlockquote, ok := child.(*ast.Blockquote); ok {
 			// ネストした引用を処理（改行を追加）
-			buffer.WriteString("\n")
-			processNestedBlockquote(buffer, nestedBlockquote, source, 2)
+			w.WriteString("\n")
+			if err := r.writeNestedBlockquote(w, nestedBlockquote, source, 2); err != nil {
+				return err
+			}
+		} else {
+			warnBlockquoteContent(w, child)
 		}
 	}
 
 	// 引用ブロックの後に続く要素がある場合は改行を追加
-	if blockquote.NextSibling() != nil {
-		buffer.WriteString("\n\n")
+	if r.hasNextBlock(blockquote) {
+		w.WriteString("\n\n")
 	}
+	return nil
 }
 
-// processNestedBlockquote はネストした引用を処理します
-func processNestedBlockquote(buffer *bytes.Buffer, blockquote *ast.Blockquote, source []byte, level int) {
+// writeNestedBlockquote はネストした引用を処理します
+func (r *backlogRenderer) writeNestedBlockquote(w *Writer, blockquote *ast.Blockquote, source []byte, level int) error {
 	for child := blockquote.FirstChild(); child != nil; child = child.NextSibling() {
 		if paragraph, ok := child.(*ast.Paragraph); ok {
 			// パラグラフ内のテキストを取得
-			var textContent strings.Builder
-			for textChild := paragraph.FirstChild(); textChild != nil; textChild = textChild.NextSibling() {
-				if textNode, ok := textChild.(*ast.Text); ok {
-					textContent.Write(textNode.Segment.Value(source))
-				}
+			text, err := r.inlineLine(w, source, paragraph)
+			if err != nil {
+				return err
 			}
 
 			// 引用プレフィックスを生成
 			prefix := strings.Repeat("> ", level)
-			buffer.WriteString(prefix + textContent.String())
+			w.WriteString(prefix + text)
 
 			// 次の子要素がある場合は改行を追加
 			if child.NextSibling() != nil {
-				buffer.WriteString("\n")
+				w.WriteString("\n")
 			}
+		} else {
+			warnBlockquoteContent(w, child)
 		}
 	}
+	return nil
 }
 
-// isChildOfBlockquote はノードが引用の子要素かどうかを判定します
-func isChildOfBlockquote(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*ast.Blockquote); ok {
-			return true
-		}
-		parent = parent.Parent()
+// warnBlockquoteContent は引用内で出力できない要素（段落とネストした引用以外）を警告します
+func warnBlockquoteContent(w *Writer, node ast.Node) {
+	if node.Kind() == ast.KindBlockquote {
+		w.Warn(node, "blockquote nested more than two levels is not supported and was removed")
+		return
 	}
-	return false
+	w.Warn(node, "%s in a blockquote is not supported and was removed", blockName(node))
 }
 
-// writeTable はテーブルノードをBacklog記法で出力します
-func writeTable(buffer *bytes.Buffer, table ast.Node, source []byte) {
-	gfmTable := table.(*gast.Table)
+// blockName は警告で使うブロック要素の名前を返します
+func blockName(node ast.Node) string {
+	switch node.(type) {
+	case *ast.Heading:
+		return "heading"
+	case *ast.List:
+		return "list"
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		return "code block"
+	case *ast.HTMLBlock:
+		return "raw HTML"
+	case *ast.ThematicBreak:
+		return "thematic break"
+	case *gast.Table:
+		return "table"
+	}
+	return "block"
+}
 
+// writeTable はテーブルノードをBacklog記法で出力します
+func (r *backlogRenderer) writeTable(w *Writer, table *gast.Table, source []byte) error {
 	// テーブルの子要素を処理
-	for child := gfmTable.FirstChild(); child != nil; child = child.NextSibling() {
-		switch childNode := child.(type) {
+	for child := table.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.(type) {
 		case *gast.TableHeader:
-			// ヘッダー行を出力
-			writeTableHeader(buffer, childNode, source)
+			// ヘッダー行を出力（セルの先頭に * を付ける）
+			if err := r.writeTableRow(w, child, source, "*"); err != nil {
+				return err
+			}
 		case *gast.TableRow:
 			// データ行を出力
-			writeTableRow(buffer, childNode, source)
+			if err := r.writeTableRow(w, child, source, ""); err != nil {
+				return err
+			}
 		}
 	}
 
 	// テーブルの後に続く要素がある場合は改行を追加
-	if table.NextSibling() != nil {
-		buffer.WriteString("\n")
+	if r.hasNextBlock(table) {
+		w.WriteString("\n")
 	}
+	return nil
 }
 
-// writeTableHeader はテーブルヘッダーを出力します
-func writeTableHeader(buffer *bytes.Buffer, tableHeader *gast.TableHeader, source []byte) {
-	buffer.WriteString("|")
-
-	// ヘッダーセルを直接処理
-	for cell := tableHeader.FirstChild(); cell != nil; cell = cell.NextSibling() {
-		if tableCell, ok := cell.(*gast.TableCell); ok {
-			buffer.WriteString("*")
-
-			// セル内のテキストを取得
-			for cellChild := tableCell.FirstChild(); cellChild != nil; cellChild = cellChild.NextSibling() {
-				if textNode, ok := cellChild.(*ast.Text); ok {
-					text := strings.TrimSpace(string(textNode.Segment.Value(source)))
-					buffer.WriteString(text)
-				}
-			}
-
-			buffer.WriteString("|")
-		}
-	}
-	buffer.WriteString("\n")
-}
-
-// writeTableRow はテーブル行を出力します
-func writeTableRow(buffer *bytes.Buffer, tableRow *gast.TableRow, source []byte) {
-	buffer.WriteString("|")
-
-	// セル内容を出力
-	for cell := tableRow.FirstChild(); cell != nil; cell = cell.NextSibling() {
-		if tableCell, ok := cell.(*gast.TableCell); ok {
-			// セル内のテキストを取得
-			for cellChild := tableCell.FirstChild(); cellChild != nil; cellChild = cellChild.NextSibling() {
-				if textNode, ok := cellChild.(*ast.Text); ok {
-					text := strings.TrimSpace(string(textNode.Segment.Value(source)))
-					buffer.WriteString(text)
-				}
+// writeTableRow はテーブルのヘッダー行・データ行を出力します（cellPrefixは各セルの先頭に付ける記号です）
+func (r *backlogRenderer) writeTableRow(w *Writer, row ast.Node, source []byte, cellPrefix string) error {
+	w.WriteString("|")
+	for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+		if _, ok := cell.(*gast.TableCell); ok {
+			text, err := r.inlineLine(w, source, cell)
+			if err != nil {
+				return err
 			}
-
-			buffer.WriteString("|")
-		}
-	}
-	buffer.WriteString("\n")
-}
-
-// isChildOfTable はノードがテーブルの子要素かどうかを判定します
-func isChildOfTable(node ast.Node) bool {
-	parent := node.Parent()
-	for parent != nil {
-		if _, ok := parent.(*gast.Table); ok {
-			return true
-		}
-		if _, ok := parent.(*gast.TableRow); ok {
-			return true
-		}
-		if _, ok := parent.(*gast.TableCell); ok {
-			return true
+			w.WriteString(cellPrefix + strings.TrimSpace(text) + "|")
 		}
-		parent = parent.Parent()
 	}
-	return false
+	w.WriteString("\n")
+	return nil
 }
@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -197,6 +198,12 @@ func TestConvert(t *testing.T) {
 			expected: "[[日本語サイト:https://日本語.com/パス]]",
 			hasError: false,
 		},
+		{
+			name:     "画像はそのまま出力",
+			input:    "![代替テキスト](http://example.com/image.png)",
+			expected: "![代替テキスト](http://example.com/image.png)",
+			hasError: false,
+		},
 		{
 			name:     "基本インラインコード変換",
 			input:    "`inline code`",
@@ -287,6 +294,16 @@ func TestConvert(t *testing.T) {
 			expected: "結果一覧:\n\n|*項目|*値|\n|A|1|\n|B|2|\n\n以上です。",
 			hasError: false,
 		},
+		{
+			name:     "バックスラッシュのエスケープは出力しない",
+			input:    "\\*強調ではない\\* と \\[括弧\\]\n\n# 見出し\\_1",
+			expected: "*強調ではない* と [括弧]\n\n* 見出し_1",
+		},
+		{
+			name:     "表のセルの区切り文字のエスケープは残す",
+			input:    "| a\\|b |\n|---|\n| x |",
+			expected: "|*a\\|b|\n|x|",
+		},
 	}
 
 	for _, tt := range tests {
@@ -309,3 +326,171 @@ func TestConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertHeadingOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "見出しレベルを1つ下げる",
+			input:    "# 見出し1\n## 見出し2",
+			opts:     []Option{WithHeadingOffset(1)},
+			expected: "** 見出し1\n*** 見出し2",
+		},
+		{
+			name:     "ずらした見出しはBacklogの最大レベルに丸める",
+			input:    "##### 見出し5\n###### 見出し6",
+			opts:     []Option{WithHeadingOffset(2)},
+			expected: "****** 見出し5\n****** 見出し6",
+		},
+		{
+			name:     "負のずらしは1に丸める",
+			input:    "# 見出し1\n### 見出し3",
+			opts:     []Option{WithHeadingOffset(-1)},
+			expected: "* 見出し1\n** 見出し3",
+		},
+		{
+			name:     "最大レベルを指定",
+			input:    "## 見出し2\n#### 見出し4",
+			opts:     []Option{WithMaxHeadingLevel(3)},
+			expected: "** 見出し2\n*** 見出し4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertDetailedTitleFromFirstH1(t *testing.T) {
+	input := "# リリース計画\n\n概要です。\n\n# 付録"
+
+	result, err := ConvertDetailed(input, WithTitleFromFirstH1(), WithHeadingOffset(1))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	if result.Title != "リリース計画" {
+		t.Errorf("期待値: %q, 実際の値: %q", "リリース計画", result.Title)
+	}
+	expectedBody := "概要です。\n\n** 付録"
+	if result.Body != expectedBody {
+		t.Errorf("期待値: %q, 実際の値: %q", expectedBody, result.Body)
+	}
+}
+
+func TestConvertDetailedTitleKeepsAutoLink(t *testing.T) {
+	result, err := ConvertDetailed("# 仕様 <https://example.com/spec>\n\n本文", WithTitleFromFirstH1())
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := "仕様 https://example.com/spec"
+	if result.Title != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, result.Title)
+	}
+}
+
+// largeDocument はベンチマーク用に指定サイズ以上の一般的なMarkdown文書を生成します
+func largeDocument(size int) string {
+	section := "## 見出し\n\n通常のテキストと**太字**と*斜体*と~~打ち消し線~~、`コード`と[リンク](http://example.com)。\n\n" +
+		"- 項目1\n  - 項目2\n    - 項目3 **太字**\n- 項目4\n\n" +
+		"1. 手順1\n2. 手順2\n\n" +
+		"> 引用\n> > ネストした引用\n\n" +
+		"| 列1 | 列2 |\n|-----|-----|\n| A | B |\n\n" +
+		"```go\nfunc main() {}\n```\n\n"
+
+	var sb strings.Builder
+	for sb.Len() < size {
+		sb.WriteString(section)
+	}
+	return sb.String()
+}
+
+// deeplyNestedDocument はベンチマーク用に深くネストしたリストの文書を生成します
+func deeplyNestedDocument(depth, repeat int) string {
+	var sb strings.Builder
+	for r := 0; r < repeat; r++ {
+		for d := 0; d < depth; d++ {
+			sb.WriteString(strings.Repeat("  ", d) + "- 項目 **太字** と `コード` と [リンク](http://example.com)\n")
+		}
+		sb.WriteString("\n段落\n\n")
+	}
+	return sb.String()
+}
+
+func BenchmarkConvertLargeDocument(b *testing.B) {
+	input := largeDocument(4 << 20)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Convert(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertDeeplyNested(b *testing.B) {
+	input := deeplyNestedDocument(50, 400)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Convert(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertLongOrderedListToMarkdown(b *testing.B) {
+	// 番号付きリストの項目番号を前の兄弟ノードから数えると項目数の2乗に比例する
+	var sb strings.Builder
+	for i := 0; sb.Len() < 2<<20; i++ {
+		sb.WriteString("1. 手順\n")
+	}
+	input := sb.String()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Convert(input, WithFormat(FormatMarkdown)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertManyWarnings(b *testing.B) {
+	// 警告のたびに行番号をソースの先頭から数えるとソースの大きさと警告数の積に比例する
+	var sb strings.Builder
+	for sb.Len() < 2<<20 {
+		sb.WriteString("テキスト\n\n<div>HTML</div>\n\n")
+	}
+	input := sb.String()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ConvertDetailed(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertLargeDocumentToMarkdown(b *testing.B) {
+	input := largeDocument(4 << 20)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Convert(input, WithFormat(FormatMarkdown)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Warning は変換時に検出した非対応の記法や情報が失われる変換を表します
+type Warning struct {
+	// Line は警告の対象となるMarkdown上の行番号（1始まり、不明な場合は0）です
+	Line int `json:"line,omitempty"`
+	// Message は警告の内容です
+	Message string `json:"message"`
+}
+
+// String は "行番号: 内容" 形式の文字列を返します
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return w.Message
+	}
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// diagnostics は変換中の警告を収集します
+type diagnostics struct {
+	source     []byte
+	warnings   []Warning
+	lineStarts []int // 各行の開始位置（最初の警告時に計算します）
+}
+
+// warn はノードの位置とともに警告を記録します
+func (d *diagnostics) warn(node ast.Node, format string, args ...any) {
+	d.warnings = append(d.warnings, Warning{
+		Line:    d.lineOf(node),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// lineOf はノードのMarkdown上の行番号を返します
+// 警告のたびにソースを数え直さないよう、行の開始位置から二分探索します
+func (d *diagnostics) lineOf(node ast.Node) int {
+	offset := nodeOffset(node)
+	if offset < 0 {
+		return 0
+	}
+	if d.lineStarts == nil {
+		d.lineStarts = []int{0}
+		for i, b := range d.source {
+			if b == '\n' {
+				d.lineStarts = append(d.lineStarts, i+1)
+			}
+		}
+	}
+	return sort.SearchInts(d.lineStarts, offset+1)
+}
+
+// nodeOffset はノードのMarkdown上の開始位置を返します（不明な場合は-1）
+func nodeOffset(node ast.Node) int {
+	if offset := ownOffset(node); offset >= 0 {
+		return offset
+	}
+
+	// インライン要素は親の位置を使う
+	if parent := node.Parent(); parent != nil && node.Type() == ast.TypeInline {
+		return nodeOffset(parent)
+	}
+	return -1
+}
+
+// ownOffset はノード自身または子孫から開始位置を探します（不明な場合は-1）
+func ownOffset(node ast.Node) int {
+	switch n := node.(type) {
+	case *ast.Text:
+		return n.Segment.Start
+	case *ast.RawHTML:
+		if n.Segments.Len() > 0 {
+			return n.Segments.At(0).Start
+		}
+	}
+
+	if node.Type() == ast.TypeBlock && node.Lines().Len() > 0 {
+		return node.Lines().At(0).Start
+	}
+
+	// 子要素から位置を探す
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if offset := ownOffset(child); offset >= 0 {
+			return offset
+		}
+	}
+	return -1
+}
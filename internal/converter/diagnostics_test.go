@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertWarnings(t *testing.T) {
+	input := "# 見出し\n\n<div>HTML</div>\n\n    indented\n\n1. 項目\n   1. ネスト\n\n- [x] 完了"
+
+	result, err := ConvertDetailed(input)
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := []Warning{
+		{Line: 3, Message: "raw HTML is not supported and was removed"},
+		{Line: 5, Message: "indented code block is not supported and was removed; use a fenced code block"},
+		{Line: 8, Message: "nested numbered list was flattened"},
+		{Line: 10, Message: "task list checkbox is not supported and was removed"},
+	}
+	if len(result.Warnings) != len(expected) {
+		t.Fatalf("期待値: %+v, 実際の値: %+v", expected, result.Warnings)
+	}
+	for i, warning := range result.Warnings {
+		if warning != expected[i] {
+			t.Errorf("期待値: %+v, 実際の値: %+v", expected[i], warning)
+		}
+	}
+}
+
+func TestConvertWithoutWarnings(t *testing.T) {
+	result, err := ConvertDetailed("# 見出し\n\n- 項目\n\n<!-- toc -->")
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("警告は出ないはずです: %+v", result.Warnings)
+	}
+}
+
+func TestConvertWarningsForRemovedContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Warning
+	}{
+		{
+			name:     "リストアイテムの続く段落",
+			input:    "- 項目\n\n  続きの段落\n- 次の項目",
+			expected: []Warning{{Line: 3, Message: "paragraph break in a list item was replaced with a space"}},
+		},
+		{
+			name:     "リストアイテムのネストしたリストの後の段落",
+			input:    "- 項目\n  - 子\n\n  続きの段落\n- 次の項目",
+			expected: []Warning{{Line: 4, Message: "paragraph after the first in a list item is not supported and was removed"}},
+		},
+		{
+			name:  "引用内の見出しとリスト",
+			input: "> # 見出し\n> - 項目",
+			expected: []Warning{
+				{Line: 1, Message: "heading in a blockquote is not supported and was removed"},
+				{Line: 2, Message: "list in a blockquote is not supported and was removed"},
+			},
+		},
+		{
+			name:     "3段以上の引用",
+			input:    "> a\n> > b\n> > > c",
+			expected: []Warning{{Line: 3, Message: "blockquote nested more than two levels is not supported and was removed"}},
+		},
+		{
+			name:     "出力される内容",
+			input:    "- 項目\n  - ネスト\n\n> 引用\n> > ネスト",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertDetailed(tt.input)
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if !reflect.DeepEqual(result.Warnings, tt.expected) {
+				t.Errorf("期待値: %+v, 実際の値: %+v", tt.expected, result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// DiagramLanguages はWithCodeBlockHookで言語を省略した場合にフックの対象とする言語です
+// Backlogでは表示できない数式・図のコードブロックです
+var DiagramLanguages = []string{"math", "mermaid", "plantuml"}
+
+// CodeBlock はフックに渡すフェンスコードブロックです
+type CodeBlock struct {
+	// Language は情報文字列の最初の語（言語名）です
+	Language string
+	// Info は情報文字列全体です（属性を含みます）
+	Info string
+	// Content はコードブロックの内容です
+	Content string
+}
+
+// CodeBlockOutput はフックによるコードブロックの変換結果です
+type CodeBlockOutput struct {
+	// Image はコードブロックの代わりに表示する画像のパスまたはURLです（空の場合はコードブロックのまま出力）
+	// WithLocalAttachments を指定した場合、ローカルの画像は添付ファイルとして登録し #image で参照します
+	Image string
+	// Caption はブロックの上に太字で表示する説明です（空の場合は表示しません）
+	Caption string
+}
+
+// CodeBlockHook はコードブロックを画像や説明付きのブロックに変換します
+type CodeBlockHook interface {
+	// HandleCodeBlock はコードブロックの変換結果を返します（nilの場合はそのまま出力）
+	HandleCodeBlock(block CodeBlock) (*CodeBlockOutput, error)
+}
+
+// CodeBlockHookFunc は関数をCodeBlockHookとして使うためのアダプタです
+type CodeBlockHookFunc func(block CodeBlock) (*CodeBlockOutput, error)
+
+// HandleCodeBlock はf(block)を呼び出します
+func (f CodeBlockHookFunc) HandleCodeBlock(block CodeBlock) (*CodeBlockOutput, error) {
+	return f(block)
+}
+
+// codeBlockHook はフックと対象の言語を保持します
+type codeBlockHook struct {
+	hook      CodeBlockHook
+	languages map[string]bool
+}
+
+// handle はフックの対象のコードブロックであればフックを呼び出して変換結果を返します
+// フックが失敗した場合は警告を記録し、コードブロックのまま出力します
+func (h *codeBlockHook) handle(w *Writer, node *ast.FencedCodeBlock, source []byte) *CodeBlockOutput {
+	if h == nil {
+		return nil
+	}
+	var info string
+	if node.Info != nil {
+		info = strings.TrimSpace(string(node.Info.Segment.Value(source)))
+	}
+	language := parseCodeInfo(info).language
+	if !h.languages[strings.ToLower(language)] {
+		return nil
+	}
+
+	block := CodeBlock{Language: language, Info: info, Content: string(node.Lines().Value(source))}
+	output, err := h.hook.HandleCodeBlock(block)
+	if err != nil {
+		w.Warn(node, "%s block was kept as code: %v", language, err)
+		return nil
+	}
+	return output
+}
+
+// isExternalURL はスキーム付きのURLかどうかを判定します（ローカルのパスと区別するため）
+func isExternalURL(destination string) bool {
+	i := strings.Index(destination, "://")
+	return i > 0 && !strings.ContainsAny(destination[:i], "/\\")
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConvertWithCodeBlockHook(t *testing.T) {
+	input := "前\n\n```mermaid caption=\"フロー\"\ngraph TD\n```\n\n```go\nfunc main() {}\n```\n\n後ろ"
+
+	tests := []struct {
+		name     string
+		hook     CodeBlockHookFunc
+		opts     []Option
+		expected string
+	}{
+		{
+			name: "画像に置き換え",
+			hook: func(block CodeBlock) (*CodeBlockOutput, error) {
+				return &CodeBlockOutput{Image: "https://example.com/" + block.Language + ".png"}, nil
+			},
+			expected: "前\n\n![mermaid](https://example.com/mermaid.png)\n>{code:go}\nfunc main() {}\n{/code}<\n後ろ",
+		},
+		{
+			name: "説明を付けてコードブロックのまま出力",
+			hook: func(block CodeBlock) (*CodeBlockOutput, error) {
+				return &CodeBlockOutput{Caption: "図: " + block.Language}, nil
+			},
+			expected: "前\n\n''図: mermaid''\n>{code:mermaid}\ngraph TD\n{/code}<\n>{code:go}\nfunc main() {}\n{/code}<\n後ろ",
+		},
+		{
+			name: "nilの場合はそのまま出力",
+			hook: func(block CodeBlock) (*CodeBlockOutput, error) {
+				return nil, nil
+			},
+			expected: "前\n\n>{code:mermaid}\ngraph TD\n{/code}<\n>{code:go}\nfunc main() {}\n{/code}<\n後ろ",
+		},
+		{
+			name: "Markdown出力で画像と説明に置き換え",
+			hook: func(block CodeBlock) (*CodeBlockOutput, error) {
+				return &CodeBlockOutput{Image: "flow.png", Caption: "フロー"}, nil
+			},
+			opts:     []Option{WithFormat(FormatMarkdown)},
+			expected: "前\n\n**フロー**\n![フロー](flow.png)\n\n```go\nfunc main() {}\n```\n\n後ろ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(input, append(tt.opts, WithCodeBlockHook(tt.hook))...)
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCodeBlockHookReceivesBlock(t *testing.T) {
+	var received []CodeBlock
+	hook := CodeBlockHookFunc(func(block CodeBlock) (*CodeBlockOutput, error) {
+		received = append(received, block)
+		return nil, nil
+	})
+
+	input := "```PlantUML title=\"seq\"\nA -> B\n```\n\n```sh\necho\n```\n\n```math\nx^2\n```"
+	if _, err := Convert(input, WithCodeBlockHook(hook, "plantuml", "sh")); err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := []CodeBlock{
+		{Language: "PlantUML", Info: "PlantUML title=\"seq\"", Content: "A -> B\n"},
+		{Language: "sh", Info: "sh", Content: "echo\n"},
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, received)
+	}
+}
+
+func TestCodeBlockHookAttachment(t *testing.T) {
+	hook := CodeBlockHookFunc(func(block CodeBlock) (*CodeBlockOutput, error) {
+		return &CodeBlockOutput{Image: "/tmp/diagrams/" + block.Language + ".png"}, nil
+	})
+
+	result, err := ConvertDetailed("```mermaid\ngraph TD\n```", WithCodeBlockHook(hook), WithLocalAttachments())
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if result.Body != "#image(mermaid.png)" {
+		t.Errorf("期待値: %q, 実際の値: %q", "#image(mermaid.png)", result.Body)
+	}
+	expected := []Attachment{{Path: "/tmp/diagrams/mermaid.png", Name: "mermaid.png", Image: true, Generated: true}}
+	if !reflect.DeepEqual(result.Attachments, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, result.Attachments)
+	}
+}
+
+func TestCodeBlockHookError(t *testing.T) {
+	hook := CodeBlockHookFunc(func(block CodeBlock) (*CodeBlockOutput, error) {
+		return nil, errors.New("mmdc not found")
+	})
+
+	result, err := ConvertDetailed("```mermaid\ngraph TD\n```", WithCodeBlockHook(hook))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if result.Body != ">{code:mermaid}\ngraph TD\n{/code}<" {
+		t.Errorf("コードブロックのまま出力されませんでした: %q", result.Body)
+	}
+	expected := []Warning{{Line: 2, Message: "mermaid block was kept as code: mmdc not found"}}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("期待値: %v, 実際の値: %v", expected, result.Warnings)
+	}
+}
@@ -0,0 +1,163 @@
+package converter
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// KindEmoji は絵文字ノードの種類です
+var KindEmoji = ast.NewNodeKind("Emoji")
+
+// Emoji は :shortcode: 形式の絵文字を表すインラインノードです
+type Emoji struct {
+	ast.BaseInline
+	// ShortCode はコロンを除いたショートコードです
+	ShortCode string
+	// Value は出力する文字列です
+	Value string
+}
+
+// Kind はノードの種類を返します
+func (n *Emoji) Kind() ast.NodeKind {
+	return KindEmoji
+}
+
+// Dump はデバッグ用にノードの内容を出力します
+func (n *Emoji) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"ShortCode": n.ShortCode, "Value": n.Value}, nil)
+}
+
+// DefaultEmoji はよく使われるショートコードとUnicode絵文字の対応表です
+var DefaultEmoji = map[string]string{
+	"+1":                         "👍",
+	"-1":                         "👎",
+	"thumbsup":                   "👍",
+	"thumbsdown":                 "👎",
+	"smile":                      "😄",
+	"smiley":                     "😃",
+	"grinning":                   "😀",
+	"laughing":                   "😆",
+	"joy":                        "😂",
+	"wink":                       "😉",
+	"blush":                      "😊",
+	"slightly_smiling_face":      "🙂",
+	"thinking":                   "🤔",
+	"sweat_smile":                "😅",
+	"cry":                        "😢",
+	"sob":                        "😭",
+	"scream":                     "😱",
+	"angry":                      "😠",
+	"heart":                      "❤️",
+	"broken_heart":               "💔",
+	"star":                       "⭐",
+	"sparkles":                   "✨",
+	"fire":                       "🔥",
+	"tada":                       "🎉",
+	"rocket":                     "🚀",
+	"zap":                        "⚡",
+	"bulb":                       "💡",
+	"memo":                       "📝",
+	"pencil":                     "📝",
+	"book":                       "📖",
+	"bookmark":                   "🔖",
+	"calendar":                   "📆",
+	"clock":                      "🕒",
+	"hourglass":                  "⌛",
+	"lock":                       "🔒",
+	"unlock":                     "🔓",
+	"key":                        "🔑",
+	"bell":                       "🔔",
+	"mag":                        "🔍",
+	"link":                       "🔗",
+	"bug":                        "🐛",
+	"wrench":                     "🔧",
+	"hammer":                     "🔨",
+	"gear":                       "⚙️",
+	"package":                    "📦",
+	"construction":               "🚧",
+	"warning":                    "⚠️",
+	"no_entry":                   "⛔",
+	"x":                          "❌",
+	"heavy_check_mark":           "✔️",
+	"white_check_mark":           "✅",
+	"ballot_box_with_check":      "☑️",
+	"question":                   "❓",
+	"exclamation":                "❗",
+	"information_source":         "ℹ️",
+	"arrow_right":                "➡️",
+	"arrow_left":                 "⬅️",
+	"arrow_up":                   "⬆️",
+	"arrow_down":                 "⬇️",
+	"ok":                         "🆗",
+	"new":                        "🆕",
+	"eyes":                       "👀",
+	"clap":                       "👏",
+	"pray":                       "🙏",
+	"muscle":                     "💪",
+	"wave":                       "👋",
+	"ok_hand":                    "👌",
+	"raised_hands":               "🙌",
+	"point_right":                "👉",
+	"100":                        "💯",
+	"coffee":                     "☕",
+	"beer":                       "🍺",
+	"sunny":                      "☀️",
+	"cloud":                      "☁️",
+	"umbrella":                   "☔",
+	"snowflake":                  "❄️",
+	"recycle":                    "♻️",
+	"chart_with_upwards_trend":   "📈",
+	"chart_with_downwards_trend": "📉",
+	"email":                      "📧",
+	"telephone":                  "☎️",
+	"computer":                   "💻",
+	"iphone":                     "📱",
+	"red_circle":                 "🔴",
+	"large_blue_circle":          "🔵",
+	"green_heart":                "💚",
+	"yellow_heart":               "💛",
+	"blue_heart":                 "💙",
+	"purple_heart":               "💜",
+}
+
+// emojiParser は :shortcode: をEmojiノードとして解析するインラインパーサーです
+type emojiParser struct {
+	emoji map[string]string
+}
+
+// Trigger はパーサーを起動する文字を返します
+func (p *emojiParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+// Parse は :shortcode: を解析します。対応表にないショートコードはテキストのまま残します
+func (p *emojiParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	end := 1
+	for end < len(line) && isShortCodeChar(line[end]) {
+		end++
+	}
+	if end == 1 || end >= len(line) || line[end] != ':' {
+		return nil
+	}
+
+	shortCode := string(line[1:end])
+	value, ok := p.emoji[shortCode]
+	if !ok {
+		return nil
+	}
+
+	block.Advance(end + 1)
+	return &Emoji{ShortCode: shortCode, Value: value}
+}
+
+// isShortCodeChar はショートコードに使える文字かどうかを判定します
+func isShortCodeChar(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '_' || c == '+' || c == '-'
+}
+
+// writeEmoji は絵文字を出力します
+func writeEmoji(w *Writer, emoji *Emoji) {
+	w.WriteString(emoji.Value)
+}
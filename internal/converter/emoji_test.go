@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertWithEmoji(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		custom   map[string]string
+		expected string
+	}{
+		{
+			name:     "ショートコードをUnicodeに変換",
+			input:    "リリースしました :tada: :+1:",
+			expected: "リリースしました 🎉 👍",
+		},
+		{
+			name:     "未知のショートコードはそのまま",
+			input:    ":unknown_emoji: です",
+			expected: ":unknown_emoji: です",
+		},
+		{
+			name:     "時刻のようなコロンは変換しない",
+			input:    "10:30:00 に開始",
+			expected: "10:30:00 に開始",
+		},
+		{
+			name:     "独自の対応表を優先",
+			input:    ":tada: :backlog:",
+			custom:   map[string]string{"tada": "(祝)", "backlog": "&(backlog)"},
+			expected: "(祝) &(backlog)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithEmoji(tt.custom))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertWithoutEmoji(t *testing.T) {
+	result, err := Convert(":tada:")
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if result != ":tada:" {
+		t.Errorf("オプションなしでは変換しないはずです: %q", result)
+	}
+}
@@ -0,0 +1,23 @@
+package converter
+
+import (
+	"github.com/yuin/goldmark/extension"
+)
+
+// typographicSubstitutions はTypographerで置き換える記号です
+// goldmarkの既定はHTMLの実体参照のため、Unicodeの文字に置き換えます
+var typographicSubstitutions = map[extension.TypographicPunctuation]string{
+	extension.LeftSingleQuote:  "‘",
+	extension.RightSingleQuote: "’",
+	extension.LeftDoubleQuote:  "“",
+	extension.RightDoubleQuote: "”",
+	extension.EnDash:           "–",
+	extension.EmDash:           "—",
+	extension.Ellipsis:         "…",
+	extension.LeftAngleQuote:   "«",
+	extension.RightAngleQuote:  "»",
+	extension.Apostrophe:       "’",
+}
+
+// definitionIndent は定義リストの説明の行頭に付けるインデントです
+const definitionIndent = "  "
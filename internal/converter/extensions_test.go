@@ -0,0 +1,102 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertWithExtensions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "定義リストの用語を太字、説明をインデントして出力",
+			input:    "用語1\n: 説明1\n: 説明2\n\n*用語2*\n:   長い説明\n    続き",
+			opts:     []Option{WithDefinitionList()},
+			expected: "''用語1''\n  説明1\n  説明2\n''用語2''\n  長い説明\n  続き",
+		},
+		{
+			name:     "Markdown出力では定義リストの説明をリスト項目として出力",
+			input:    "用語\n: 説明1\n: 説明2",
+			opts:     []Option{WithDefinitionList(), WithFormat(FormatMarkdown)},
+			expected: "**用語**\n- 説明1\n- 説明2",
+		},
+		{
+			name:     "オプションなしでは定義リストを解釈しない",
+			input:    "用語\n: 説明",
+			expected: "用語\n: 説明",
+		},
+		{
+			name:     "引用符やダッシュを組版用の記号に置き換え",
+			input:    "\"Hello\" -- it's... --- done\n\n# \"見出し\"",
+			opts:     []Option{WithTypographer()},
+			expected: "“Hello” – it’s… — done\n\n* “見出し”",
+		},
+		{
+			name:     "URLとメールアドレスをリンクとして解釈",
+			input:    "see https://example.com or mail@example.com",
+			opts:     []Option{WithLinkify()},
+			expected: "see https://example.com or mail@example.com",
+		},
+		{
+			name:     "スキームのないURLはリンク記法で出力",
+			input:    "see www.example.com now",
+			opts:     []Option{WithLinkify()},
+			expected: "see [[www.example.com:http://www.example.com]] now",
+		},
+		{
+			name:     "見出しのURLをリンクとして解釈",
+			input:    "# See https://example.com now",
+			opts:     []Option{WithLinkify()},
+			expected: "* See https://example.com now",
+		},
+		{
+			name:     "引用のURLをリンクとして解釈",
+			input:    "> see https://example.com now",
+			opts:     []Option{WithLinkify()},
+			expected: "> see https://example.com now",
+		},
+		{
+			name:     "表のセルのURLをリンクとして解釈",
+			input:    "| url |\n| --- |\n| https://example.com |",
+			opts:     []Option{WithLinkify()},
+			expected: "|*url|\n|https://example.com|",
+		},
+		{
+			name:     "リストアイテムのURLをリンクとして解釈",
+			input:    "- see https://example.com now\n- next",
+			opts:     []Option{WithLinkify()},
+			expected: "- see https://example.com now\n- next",
+		},
+		{
+			name:     "リストアイテムのURLをリンク記法で出力",
+			input:    "- see https://example.com now",
+			opts:     []Option{WithLinkify(), WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "- see [[https://example.com:https://example.com]] now",
+		},
+		{
+			name:     "オプションなしではURLをテキストのまま出力",
+			input:    "see https://example.com and @user",
+			expected: "see https://example.com and @user",
+		},
+		{
+			name:     "山括弧のURLはオプションなしでもリンクとして出力",
+			input:    "see <https://example.com>",
+			expected: "see https://example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	gast "github.com/yuin/goldmark/extension/ast"
+)
+
+// FootnoteStyle は脚注の出力形式です
+type FootnoteStyle int
+
+const (
+	// FootnoteNone は脚注記法を解釈せず、そのままのテキストとして出力します
+	FootnoteNone FootnoteStyle = iota
+	// FootnoteNotes は参照箇所に [1] 形式の番号を付け、本文の末尾の「Notes」に脚注をまとめます
+	FootnoteNotes
+	// FootnoteInline は参照箇所に脚注の内容を括弧書きで展開します
+	FootnoteInline
+)
+
+// footnoteSectionTitle は脚注をまとめる節の見出しです
+const footnoteSectionTitle = "Notes"
+
+// ParseFootnoteStyle は文字列から脚注の出力形式を取得します
+func ParseFootnoteStyle(s string) (FootnoteStyle, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return FootnoteNone, nil
+	case "notes":
+		return FootnoteNotes, nil
+	case "inline":
+		return FootnoteInline, nil
+	}
+	return FootnoteNone, fmt.Errorf("unknown footnote style: %q", s)
+}
+
+// footnoteMarker は脚注の番号を [1] 形式で返します
+func footnoteMarker(index int) string {
+	return "[" + strconv.Itoa(index) + "]"
+}
+
+// detachFootnotes は文書末尾の脚注の一覧を文書から取り除き、番号と脚注の対応を返します
+// 脚注を参照箇所に展開する場合に使います（一覧が最後のブロックとして残ると改行の出力が変わるため）
+func detachFootnotes(document ast.Node) map[int]*gast.Footnote {
+	footnotes := map[int]*gast.Footnote{}
+	list, ok := document.LastChild().(*gast.FootnoteList)
+	if !ok {
+		return footnotes
+	}
+	for child := list.FirstChild(); child != nil; child = child.NextSibling() {
+		if footnote, ok := child.(*gast.Footnote); ok {
+			footnotes[footnote.Index] = footnote
+		}
+	}
+	document.RemoveChild(document, list)
+	return footnotes
+}
+
+// writeInlineFootnote は脚注の内容を括弧書きで出力します
+func writeInlineFootnote(w *Writer, footnote *gast.Footnote, source []byte) {
+	if footnote == nil {
+		return
+	}
+	w.WriteString(" (" + footnoteText(footnote, source) + ")")
+}
+
+// footnoteText は脚注の内容を1行のテキストとして返します（段落や改行は空白でつなぎます）
+func footnoteText(footnote *gast.Footnote, source []byte) string {
+	var text strings.Builder
+	_ = ast.Walk(footnote, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch child := n.(type) {
+		case *ast.Paragraph:
+			if text.Len() > 0 {
+				text.WriteString(" ")
+			}
+		case *ast.Text:
+			text.Write(child.Segment.Value(source))
+			if child.SoftLineBreak() || child.HardLineBreak() {
+				text.WriteString(" ")
+			}
+		case *ast.String:
+			text.Write(child.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(text.String())
+}
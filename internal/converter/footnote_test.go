@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertWithFootnotes(t *testing.T) {
+	input := "本文[^1]と*強調*[^note]。\n\n[^1]: 最初の脚注\n    続き\n[^note]: **二つ目**\n\n    二段落目\n[^unused]: 未使用"
+
+	tests := []struct {
+		name     string
+		style    FootnoteStyle
+		format   Format
+		expected string
+	}{
+		{
+			name:     "オプションなしではそのまま出力",
+			style:    FootnoteNone,
+			format:   FormatBacklog,
+			expected: "本文[[^1:最初の脚注]]と'''強調'''[^note]。\n\n続き\n[^note]: ''二つ目''",
+		},
+		{
+			name:     "番号を付けて末尾のNotesにまとめる",
+			style:    FootnoteNotes,
+			format:   FormatBacklog,
+			expected: "本文[1]と'''強調'''[2]。\n\n''Notes''\n[1] 最初の脚注\n続き\n[2] ''二つ目''\n二段落目",
+		},
+		{
+			name:     "参照箇所に括弧書きで展開",
+			style:    FootnoteInline,
+			format:   FormatBacklog,
+			expected: "本文 (最初の脚注 続き)と'''強調''' (''二つ目'' 二段落目)。",
+		},
+		{
+			name:     "Markdown出力で末尾のNotesにまとめる",
+			style:    FootnoteNotes,
+			format:   FormatMarkdown,
+			expected: "本文[1]と*強調*[2]。\n\n**Notes**\n[1] 最初の脚注\n    続き\n[2] **二つ目**\n\n    二段落目",
+		},
+		{
+			name:     "Markdown出力で参照箇所に括弧書きで展開",
+			style:    FootnoteInline,
+			format:   FormatMarkdown,
+			expected: "本文 (最初の脚注 続き)と*強調* (二つ目 二段落目)。",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(input, WithFootnotes(tt.style), WithFormat(tt.format))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertFootnotesInContainers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		style    FootnoteStyle
+		expected string
+	}{
+		{
+			name:     "見出しで括弧書きに展開",
+			input:    "# 見出し[^1]\n\n[^1]: 注記",
+			style:    FootnoteInline,
+			expected: "* 見出し (注記)",
+		},
+		{
+			name:     "見出しで番号を付ける",
+			input:    "# 見出し[^1]\n\n[^1]: 注記",
+			style:    FootnoteNotes,
+			expected: "* 見出し[1]\n\n''Notes''\n[1] 注記",
+		},
+		{
+			name:     "引用で括弧書きに展開",
+			input:    "> 引用[^1]\n\n[^1]: 注記",
+			style:    FootnoteInline,
+			expected: "> 引用 (注記)",
+		},
+		{
+			name:     "引用で番号を付ける",
+			input:    "> 引用[^1]\n\n[^1]: 注記",
+			style:    FootnoteNotes,
+			expected: "> 引用[1]\n\n''Notes''\n[1] 注記",
+		},
+		{
+			name:     "リストアイテムで括弧書きに展開",
+			input:    "- 項目[^1]\n- 次\n\n[^1]: 注記",
+			style:    FootnoteInline,
+			expected: "- 項目 (注記)\n- 次",
+		},
+		{
+			name:     "リストアイテムで番号を付ける",
+			input:    "- 項目[^1]\n- 次\n\n[^1]: 注記",
+			style:    FootnoteNotes,
+			expected: "- 項目[1]\n- 次\n\n''Notes''\n[1] 注記",
+		},
+		{
+			name:     "括弧書きの脚注の中の記法も変換",
+			input:    "- 項目[^1]\n\n[^1]: `code` と [リンク](https://example.com)",
+			style:    FootnoteInline,
+			expected: "- 項目 ({code}code{/code} と [[リンク:https://example.com]])",
+		},
+		{
+			name:     "括弧書きの脚注のエスケープは出力しない",
+			input:    "本文[^1]\n\n[^1]: a \\* b",
+			style:    FootnoteInline,
+			expected: "本文 (a * b)",
+		},
+		{
+			name:     "自身を参照する脚注は一度だけ展開",
+			input:    "本文[^1]\n\n[^1]: 注記[^1]",
+			style:    FootnoteInline,
+			expected: "本文 (注記)",
+		},
+		{
+			name:     "表のセルで番号を付ける",
+			input:    "| a |\n| --- |\n| x[^1] |\n\n[^1]: 注記",
+			style:    FootnoteNotes,
+			expected: "|*a|\n|x[1]|\n\n''Notes''\n[1] 注記",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithFootnotes(tt.style))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseFootnoteStyle(t *testing.T) {
+	for input, expected := range map[string]FootnoteStyle{"": FootnoteNone, "none": FootnoteNone, "notes": FootnoteNotes, "INLINE": FootnoteInline} {
+		style, err := ParseFootnoteStyle(input)
+		if err != nil || style != expected {
+			t.Errorf("ParseFootnoteStyle(%q) = %v, %v; 期待値: %v", input, style, err, expected)
+		}
+	}
+
+	if _, err := ParseFootnoteStyle("superscript"); err == nil {
+		t.Error("不明な形式でエラーが発生しませんでした")
+	}
+}
@@ -0,0 +1,24 @@
+package converter
+
+import "strings"
+
+// ExtractFrontMatter は文書の先頭の --- で囲んだフロントマターを取り出し、残りの本文とともに返します
+// フロントマターがない場合は空文字列と文書全体を返します
+func ExtractFrontMatter(markdown string) (frontMatter, body string) {
+	first, rest, ok := strings.Cut(markdown, "\n")
+	if !ok || strings.TrimRight(first, " \t\r") != "---" {
+		return "", markdown
+	}
+
+	offset := 0
+	for offset < len(rest) {
+		line, _, _ := strings.Cut(rest[offset:], "\n")
+		next := offset + len(line) + 1
+		if trimmed := strings.TrimRight(line, " \t\r"); trimmed == "---" || trimmed == "..." {
+			return rest[:offset], rest[min(next, len(rest)):]
+		}
+		offset = next
+	}
+	// 閉じる --- がない場合はフロントマターとみなさない
+	return "", markdown
+}
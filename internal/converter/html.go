@@ -0,0 +1,506 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// InputFormat は変換する入力の形式です
+type InputFormat int
+
+const (
+	// InputMarkdown はMarkdownを入力とします
+	InputMarkdown InputFormat = iota
+	// InputHTML はHTMLを入力とします（Markdownに変換してから同じ出力処理で変換します）
+	InputHTML
+)
+
+// ParseInputFormat は文字列から入力の形式を取得します
+func ParseInputFormat(s string) (InputFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "markdown", "md":
+		return InputMarkdown, nil
+	case "html":
+		return InputHTML, nil
+	}
+	return InputMarkdown, fmt.Errorf("unknown input format: %q", s)
+}
+
+// htmlNode はHTMLの要素またはテキストです
+type htmlNode struct {
+	// tag は小文字の要素名です（テキストの場合は空）
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*htmlNode
+}
+
+// htmlBlockTags はブロックとして扱う要素です
+var htmlBlockTags = map[string]bool{
+	"html": true, "body": true, "div": true, "p": true, "section": true, "article": true,
+	"header": true, "footer": true, "main": true, "nav": true, "aside": true, "figure": true,
+	"figcaption": true, "address": true, "form": true, "fieldset": true, "details": true, "summary": true,
+	"center": true, "dl": true, "dt": true, "dd": true, "li": true, "ul": true, "ol": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "table": true, "hr": true,
+}
+
+// htmlVoidTags は閉じタグを持たない要素です
+var htmlVoidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlSkipTags は内容を出力しない要素です
+var htmlSkipTags = map[string]bool{
+	"head": true, "title": true, "script": true, "style": true, "template": true, "noscript": true,
+}
+
+var (
+	htmlSpaces         = regexp.MustCompile(`[ \t\r\n\f\x{00A0}]+`)
+	htmlBlockMarker    = regexp.MustCompile(`^(?:(\d{1,9})[.)]|#{1,6}|[-+]|>|[-=]+$)(?:\s|$)`)
+	htmlLanguageClass  = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#-]+)`)
+	htmlBrushParameter = regexp.MustCompile(`brush:\s*([\w+#-]+)`)
+
+	htmlEntityReference = regexp.MustCompile(`^&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+)
+
+// htmlToMarkdown はHTMLを解析し、同じ内容のMarkdownに変換します
+func htmlToMarkdown(html []byte) []byte {
+	return []byte(strings.Join(htmlBlocks(parseHTML(html)), "\n\n"))
+}
+
+// parseHTML はHTMLを要素の木に変換します
+// 要素の対応はHTMLの規則（空要素・閉じタグの省略）に従って組み立てます
+func parseHTML(html []byte) *htmlNode {
+	root := &htmlNode{tag: "body"}
+	stack := []*htmlNode{root}
+	tokenizer := newHTMLTokenizer(html)
+	for {
+		token, ok := tokenizer.next()
+		if !ok {
+			break
+		}
+
+		switch token.kind {
+		case htmlStartTagToken:
+			node := &htmlNode{tag: token.tag, attrs: token.attrs}
+			stack = closeImplicitly(stack, node.tag)
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			if !htmlVoidTags[node.tag] {
+				stack = append(stack, node)
+			}
+		case htmlEndTagToken:
+			// 対応する開始タグがない閉じタグは無視する
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].tag == token.tag {
+					stack = stack[:i]
+					break
+				}
+			}
+		case htmlTextToken:
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, &htmlNode{text: token.text})
+		}
+	}
+	return root
+}
+
+// htmlImplicitEnds は開始タグによって閉じられる要素と、それより外側を探さない境界の要素です
+var htmlImplicitEnds = map[string]struct{ closes, boundaries []string }{
+	"li":    {closes: []string{"li"}, boundaries: []string{"ul", "ol"}},
+	"dt":    {closes: []string{"dt", "dd"}, boundaries: []string{"dl"}},
+	"dd":    {closes: []string{"dt", "dd"}, boundaries: []string{"dl"}},
+	"tr":    {closes: []string{"tr"}, boundaries: []string{"table", "thead", "tbody", "tfoot"}},
+	"td":    {closes: []string{"td", "th"}, boundaries: []string{"tr", "table"}},
+	"th":    {closes: []string{"td", "th"}, boundaries: []string{"tr", "table"}},
+	"thead": {closes: []string{"thead", "tbody", "tfoot"}, boundaries: []string{"table"}},
+	"tbody": {closes: []string{"thead", "tbody", "tfoot"}, boundaries: []string{"table"}},
+	"tfoot": {closes: []string{"thead", "tbody", "tfoot"}, boundaries: []string{"table"}},
+}
+
+// closeImplicitly は開始タグによって暗黙に閉じられる要素をスタックから取り除きます
+func closeImplicitly(stack []*htmlNode, tag string) []*htmlNode {
+	if rule, ok := htmlImplicitEnds[tag]; ok {
+		for i := len(stack) - 1; i > 0; i-- {
+			if slices.Contains(rule.closes, stack[i].tag) {
+				return stack[:i]
+			}
+			if slices.Contains(rule.boundaries, stack[i].tag) {
+				break
+			}
+		}
+	}
+
+	// ブロック要素は開いているパラグラフを閉じる
+	if htmlBlockTags[tag] {
+		for i := len(stack) - 1; i > 0; i-- {
+			if stack[i].tag == "p" {
+				return stack[:i]
+			}
+			if htmlBlockTags[stack[i].tag] {
+				break
+			}
+		}
+	}
+	return stack
+}
+
+// htmlBlocks は要素の子をMarkdownのブロックの列に変換します
+// 連続するインラインの子は1つのパラグラフにまとめます
+func htmlBlocks(node *htmlNode) []string {
+	var blocks []string
+	var inline strings.Builder
+	flush := func() {
+		if paragraph := htmlParagraph(inline.String()); paragraph != "" {
+			blocks = append(blocks, paragraph)
+		}
+		inline.Reset()
+	}
+
+	for _, child := range node.children {
+		if child.tag != "" && htmlBlockTags[child.tag] {
+			flush()
+			blocks = append(blocks, htmlBlock(child)...)
+		} else {
+			inline.WriteString(htmlInline(child))
+		}
+	}
+	flush()
+	return blocks
+}
+
+// htmlBlock はブロック要素をMarkdownのブロックに変換します
+func htmlBlock(node *htmlNode) []string {
+	switch node.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := htmlParagraph(htmlInlineChildren(node))
+		if text == "" {
+			return nil
+		}
+		level := int(node.tag[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\n", " ")}
+	case "ul", "ol":
+		if list := htmlList(node); list != "" {
+			return []string{list}
+		}
+		return nil
+	case "pre":
+		return []string{htmlCodeBlock(node)}
+	case "blockquote":
+		inner := strings.Join(htmlBlocks(node), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", ">")}
+	case "table":
+		if table := htmlTable(node); table != "" {
+			return []string{table}
+		}
+		return nil
+	case "hr":
+		return []string{"---"}
+	}
+	return htmlBlocks(node)
+}
+
+// htmlInline はインラインの要素またはテキストをMarkdownに変換します
+func htmlInline(node *htmlNode) string {
+	if node.tag == "" {
+		return escapeMarkdownText(htmlSpaces.ReplaceAllString(node.text, " "))
+	}
+	if htmlSkipTags[node.tag] {
+		return ""
+	}
+
+	switch node.tag {
+	case "br":
+		return "\n"
+	case "strong", "b":
+		return wrapInline(htmlInlineChildren(node), "**")
+	case "em", "i":
+		return wrapInline(htmlInlineChildren(node), "*")
+	case "del", "s", "strike":
+		return wrapInline(htmlInlineChildren(node), "~~")
+	case "code", "kbd", "samp", "tt":
+		content := strings.TrimSpace(htmlSpaces.ReplaceAllString(htmlTextContent(node), " "))
+		if content == "" {
+			return ""
+		}
+		return markdownCodeSpan(content)
+	case "a":
+		text := strings.TrimSpace(htmlInlineChildren(node))
+		href := node.attrs["href"]
+		if href == "" {
+			return text
+		}
+		return "[" + text + "](" + markdownDestination(href) + markdownLinkTitle([]byte(node.attrs["title"])) + ")"
+	case "img":
+		src := node.attrs["src"]
+		if src == "" {
+			return ""
+		}
+		return "![" + escapeMarkdownText(node.attrs["alt"]) + "](" + markdownDestination(src) + markdownLinkTitle([]byte(node.attrs["title"])) + ")"
+	case "input":
+		if strings.EqualFold(node.attrs["type"], "checkbox") {
+			if _, checked := node.attrs["checked"]; checked {
+				return "[x] "
+			}
+			return "[ ] "
+		}
+		return ""
+	}
+	return htmlInlineChildren(node)
+}
+
+// htmlInlineChildren は子をすべてインラインとしてMarkdownに変換して連結します
+func htmlInlineChildren(node *htmlNode) string {
+	var text strings.Builder
+	for _, child := range node.children {
+		text.WriteString(htmlInline(child))
+	}
+	return text.String()
+}
+
+// htmlTextContent は要素配下のテキストをそのまま連結します（<br>は改行）
+func htmlTextContent(node *htmlNode) string {
+	if node.tag == "" {
+		return node.text
+	}
+	if node.tag == "br" {
+		return "\n"
+	}
+	var text strings.Builder
+	for _, child := range node.children {
+		text.WriteString(htmlTextContent(child))
+	}
+	return text.String()
+}
+
+// htmlParagraph はインラインのMarkdownの各行の前後の空白を除き、行頭の記号をエスケープしたパラグラフにします
+func htmlParagraph(inline string) string {
+	lines := strings.Split(inline, "\n")
+	for i, line := range lines {
+		lines[i] = escapeLineStart(strings.TrimSpace(htmlSpaces.ReplaceAllString(line, " ")))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// htmlList はリスト要素をMarkdownのリストに変換します
+func htmlList(list *htmlNode) string {
+	ordered := list.tag == "ol"
+	number := 1
+	if start, err := strconv.Atoi(list.attrs["start"]); err == nil && ordered {
+		number = start
+	}
+
+	var items []string
+	for _, child := range list.children {
+		if child.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		// 項目の中のブロックは空行で区切り、2行目以降は項目の内容として字下げする
+		// （改行だけで区切ると、続く段落が前の段落の続きの行として連結される）
+		first, rest, _ := strings.Cut(strings.Join(htmlBlocks(child), "\n\n"), "\n")
+		item := marker + first
+		if rest != "" {
+			item += "\n" + prefixLines(rest, strings.Repeat(" ", len(marker)), "")
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, "\n")
+}
+
+// htmlCodeBlock はpre要素をフェンスコードブロックに変換します
+func htmlCodeBlock(pre *htmlNode) string {
+	content := strings.TrimPrefix(htmlTextContent(pre), "\n")
+	content = strings.TrimRight(content, "\n")
+	fence := codeFence(content, "```")
+	return fence + htmlCodeLanguage(pre) + "\n" + content + "\n" + fence
+}
+
+// htmlCodeLanguage はpre要素または直下のcode要素の属性から言語名を取り出します
+// class="language-go"、Confluenceの data-syntaxhighlighter-params="brush: java" などに対応します
+func htmlCodeLanguage(pre *htmlNode) string {
+	candidates := []*htmlNode{pre}
+	for _, child := range pre.children {
+		if child.tag == "code" {
+			candidates = append(candidates, child)
+		}
+	}
+	for _, node := range candidates {
+		if language := node.attrs["data-language"]; language != "" {
+			return language
+		}
+		if m := htmlLanguageClass.FindStringSubmatch(node.attrs["class"]); m != nil {
+			return m[1]
+		}
+		for _, attr := range []string{"data-syntaxhighlighter-params", "class"} {
+			if m := htmlBrushParameter.FindStringSubmatch(node.attrs[attr]); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// htmlTable は表をGFMの表に変換します（最初の行を見出し行とします）
+func htmlTable(table *htmlNode) string {
+	var rows [][]string
+	var collect func(node *htmlNode)
+	collect = func(node *htmlNode) {
+		for _, child := range node.children {
+			switch child.tag {
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			case "tr":
+				var cells []string
+				for _, cell := range child.children {
+					if cell.tag == "th" || cell.tag == "td" {
+						text := htmlParagraph(htmlInlineChildren(cell))
+						text = strings.ReplaceAll(text, "|", "\\|")
+						cells = append(cells, strings.ReplaceAll(text, "\n", " "))
+					}
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collect(table)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapInline は内容を強調などの記号で囲みます（記号の内側に空白が来ないよう、前後の空白は外に出します）
+func wrapInline(content, marker string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return content
+	}
+	start := strings.Index(content, trimmed)
+	return content[:start] + marker + trimmed + marker + content[start+len(trimmed):]
+}
+
+// markdownDestination はリンク先に空白や括弧が含まれる場合に <> で囲みます
+func markdownDestination(destination string) string {
+	if strings.ContainsAny(destination, " ()<>") {
+		return "<" + strings.ReplaceAll(destination, ">", "%3E") + ">"
+	}
+	return destination
+}
+
+// escapeMarkdownText はテキストがMarkdownの記法として解釈されないようにエスケープします
+// Backlog記法への変換ではエスケープの \ がそのまま残るため、記法になり得る箇所だけをエスケープします
+func escapeMarkdownText(s string) string {
+	var text strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		var prev, next byte = ' ', ' '
+		if i > 0 {
+			prev = s[i-1]
+		}
+		if i+1 < len(s) {
+			next = s[i+1]
+		}
+
+		var escape bool
+		switch c {
+		case '`':
+			escape = true
+		case '*':
+			// 前後が空白の * は強調にならない
+			escape = !(isSpaceByte(prev) && isSpaceByte(next))
+		case '_':
+			// 単語中の _ は強調にならない
+			escape = !(isAlnumByte(prev) && isAlnumByte(next)) && !(isSpaceByte(prev) && isSpaceByte(next))
+		case '~':
+			escape = next == '~'
+		case '<':
+			escape = isAlphaByte(next) || next == '/' || next == '!' || next == '?'
+		case ']':
+			escape = next == '(' || next == '['
+		case '&':
+			escape = htmlEntityReference.MatchString(s[i:])
+		case '\\':
+			escape = next < 0x80 && strings.IndexByte(markdownPunctuation, next) >= 0
+		}
+		if escape {
+			text.WriteByte('\\')
+		}
+		text.WriteByte(c)
+	}
+	return text.String()
+}
+
+// markdownPunctuation はバックスラッシュでエスケープできるASCIIの記号です
+const markdownPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// isSpaceByte は空白文字かどうかを判定します
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// isAlphaByte はASCIIの英字かどうかを判定します
+func isAlphaByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isAlnumByte は英数字（またはマルチバイト文字の一部）かどうかを判定します
+func isAlnumByte(c byte) bool {
+	return isAlphaByte(c) || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// escapeLineStart は行頭で見出し・引用・リストなどとして解釈される記号をエスケープします
+func escapeLineStart(line string) string {
+	if m := htmlBlockMarker.FindStringSubmatchIndex(line); m != nil {
+		// 番号付きリストは番号の後ろの記号をエスケープする
+		if m[2] >= 0 {
+			return line[:m[3]] + "\\" + line[m[3]:]
+		}
+		return "\\" + line
+	}
+	return line
+}
+
+// prefixLines は各行の先頭に接頭辞を付けます（空行にはemptyを付けます）
+func prefixLines(s, prefix, empty string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = empty
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
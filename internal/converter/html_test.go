@@ -0,0 +1,179 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "見出しと強調",
+			input:    "<h1>タイトル</h1><p><strong>太字</strong>と<em>斜体</em>と<del>取り消し</del></p>",
+			expected: "* タイトル\n''太字''と'''斜体'''と%%取り消し%%",
+		},
+		{
+			name:     "リンクと画像",
+			input:    `<p><a href="https://example.com" title="Guide">ガイド</a> <img src="https://example.com/a.png" alt="図"></p>`,
+			expected: "[[ガイド:https://example.com]] (Guide) ![図](https://example.com/a.png)",
+		},
+		{
+			name:     "閉じタグを省略したリスト",
+			input:    "<ul><li>one<li>two<ul><li>nested</ul></ul><ol><li>first<li>second</ol>",
+			expected: "- one\n- two\n-- nested\n+ first\n+ second",
+		},
+		{
+			name:     "表",
+			input:    "<table><thead><tr><th>名前<th>値</thead><tbody><tr><td>a<td>1<tr><td>b<td>2</tbody></table>",
+			expected: "|*名前|*値|\n|a|1|\n|b|2|",
+		},
+		{
+			name:     "言語付きのコードブロック",
+			input:    "<pre><code class=\"language-go\">if a &lt; b {\n\treturn\n}\n</code></pre>",
+			expected: ">{code:go}\nif a < b {\n\treturn\n}\n{/code}<",
+		},
+		{
+			name:     "Confluenceのコードブロック",
+			input:    `<pre class="syntaxhighlighter-pre" data-syntaxhighlighter-params="brush: java; gutter: false">int x;</pre>`,
+			expected: ">{code:java}\nint x;\n{/code}<",
+		},
+		{
+			name:     "インラインコードと改行",
+			input:    "<div>実行: <code>go test</code><br>完了</div>",
+			expected: "実行: {code}go test{/code}\n完了",
+		},
+		{
+			name:     "引用",
+			input:    "<blockquote><p>引用文</p></blockquote>",
+			expected: "> 引用文",
+		},
+		{
+			name:     "記法として解釈される文字",
+			input:    "<p>1. 番号ではない</p><p># 見出しではない</p><p>snake_case と AT&amp;T</p>",
+			expected: "1. 番号ではない\n\n# 見出しではない\n\nsnake_case と AT&T",
+		},
+		{
+			name:     "記号はエスケープせずに出力",
+			input:    "<p>Use *args and __init__ or `x` and &amp;foo; or a&lt;b</p>",
+			expected: "Use *args and __init__ or `x` and &foo; or a<b",
+		},
+		{
+			name:     "見出しとリンクの記号",
+			input:    "<h2>*ptr</h2><p><a href=\"https://example.com\">a_b_c*</a></p>",
+			expected: "** *ptr\n[[a_b_c*:https://example.com]]",
+		},
+		{
+			name:     "メールクライアントのHTML",
+			input:    "<html><head><style>p { margin: 0 }</style></head><body><!-- header --><p class=MsoNormal>本文<o:p></o:p></p><script>if (a < b) {}</script></body></html>",
+			expected: "本文",
+		},
+		{
+			name:     "閉じられていない要素",
+			input:    "<p>first<p>second <b>bold",
+			expected: "first\n\nsecond ''bold''",
+		},
+		{
+			name:     "属性値に含まれる<",
+			input:    "<p onclick='a<b' class=note>x</p>",
+			expected: "x",
+		},
+		{
+			name:     "文字参照とタグにならない<",
+			input:    "<p>2 &lt; 3 &amp;&amp; a < b</p>",
+			expected: "2 < 3 && a < b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithInputFormat(InputHTML))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertHTMLToMarkdown(t *testing.T) {
+	input := "<h2>手順</h2><ol start=\"3\"><li><input type=\"checkbox\" checked> 準備<li>実行</ol><pre>make</pre>"
+	expected := "## 手順\n\n3. ☑ 準備\n4. 実行\n\n```\nmake\n```"
+
+	result, err := Convert(input, WithInputFormat(InputHTML), WithFormat(FormatMarkdown))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if result != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, result)
+	}
+}
+
+func TestConvertHTMLListItemParagraphs(t *testing.T) {
+	input := "<ol><li><p>one</p></li><li><p>two</p><p>more</p></li></ol>"
+
+	result, err := ConvertDetailed(input, WithInputFormat(InputHTML))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if expected := "+ one\n+ two more"; result.Body != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, result.Body)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Message != "paragraph break in a list item was replaced with a space" {
+		t.Errorf("警告が期待と異なります: %v", result.Warnings)
+	}
+
+	// Markdownでは段落の区切りを残す
+	markdown, err := Convert(input, WithInputFormat(InputHTML), WithFormat(FormatMarkdown))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if expected := "1. one\n\n2. two\n\n   more"; markdown != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, markdown)
+	}
+}
+
+func TestConvertToHTML(t *testing.T) {
+	var out strings.Builder
+	warnings, err := ConvertTo(&out, strings.NewReader("<p>a</p><hr><p>b</p>"), WithInputFormat(InputHTML))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+	if out.String() != "a\n\nb" {
+		t.Errorf("期待値: %q, 実際の値: %q", "a\n\nb", out.String())
+	}
+	// HTMLから変換したMarkdownの行番号は入力と対応しないため、警告に行番号を付けない
+	if len(warnings) != 1 || warnings[0].Line != 0 {
+		t.Errorf("警告が期待と異なります: %v", warnings)
+	}
+}
+
+func TestParseInputFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected InputFormat
+		wantErr  bool
+	}{
+		{input: "", expected: InputMarkdown},
+		{input: "md", expected: InputMarkdown},
+		{input: "HTML", expected: InputHTML},
+		{input: "rst", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseInputFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("エラーの有無が期待と異なります: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %v, 実際の値: %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package converter
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// htmlTokenKind はHTMLの字句の種類です
+type htmlTokenKind int
+
+const (
+	htmlTextToken htmlTokenKind = iota
+	htmlStartTagToken
+	htmlEndTagToken
+)
+
+// htmlToken はHTMLの字句（テキスト・開始タグ・閉じタグ）です
+type htmlToken struct {
+	kind htmlTokenKind
+	// tag は小文字の要素名です（Outlookの <o:p> などは接頭辞を含めた名前です）
+	tag   string
+	attrs map[string]string
+	// text は文字参照を展開したテキストです
+	text string
+}
+
+// htmlRawTextTags は閉じタグまでの内容をタグとして解釈しない要素です
+var htmlRawTextTags = map[string]bool{"script": true, "style": true}
+
+// htmlTokenizer はHTMLを字句に分割します
+// HTMLの字句規則のうち、メールやWikiのエクスポートで使われる範囲（コメント・引用符のない属性値・タグにならない < など）を扱います
+type htmlTokenizer struct {
+	input []byte
+	pos   int
+	// rawText は内容をテキストとして読む要素（script・style）の名前です
+	rawText string
+}
+
+// newHTMLTokenizer は新しいhtmlTokenizerを生成します
+func newHTMLTokenizer(input []byte) *htmlTokenizer {
+	return &htmlTokenizer{input: input}
+}
+
+// next は次の字句を返します。入力の終わりではfalseを返します
+func (t *htmlTokenizer) next() (htmlToken, bool) {
+	for t.pos < len(t.input) {
+		if t.rawText != "" {
+			return t.readRawText(), true
+		}
+		if t.input[t.pos] != '<' {
+			return t.readText(), true
+		}
+
+		rest := t.input[t.pos:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			t.skipComment()
+		case len(rest) > 1 && (rest[1] == '!' || rest[1] == '?'):
+			// 文書型宣言・処理命令・CDATAは出力しない
+			t.skipPast('>')
+		case len(rest) > 2 && rest[1] == '/' && isAlphaByte(rest[2]):
+			t.pos += 2
+			tag := t.readTagName()
+			t.skipPast('>')
+			return htmlToken{kind: htmlEndTagToken, tag: tag}, true
+		case len(rest) > 1 && rest[1] == '/':
+			// </> や </ 1> は内容のないコメントとして読み飛ばす
+			t.skipPast('>')
+		case len(rest) > 1 && isAlphaByte(rest[1]):
+			if token, ok := t.readStartTag(); ok {
+				return token, true
+			}
+			// 閉じられていないタグはテキストとして扱う
+			return t.readText(), true
+		default:
+			// タグの始まりではない < はテキスト
+			return t.readText(), true
+		}
+	}
+	return htmlToken{}, false
+}
+
+// readText は次のタグの始まりまでをテキストとして読み込みます
+func (t *htmlTokenizer) readText() htmlToken {
+	start := t.pos
+	t.pos++
+	for t.pos < len(t.input) {
+		if t.input[t.pos] == '<' && startsMarkup(t.input[t.pos:]) {
+			break
+		}
+		t.pos++
+	}
+	return htmlToken{kind: htmlTextToken, text: html.UnescapeString(string(t.input[start:t.pos]))}
+}
+
+// startsMarkup は < がタグ・コメントなどの始まりかどうかを判定します
+func startsMarkup(rest []byte) bool {
+	return len(rest) > 1 && (isAlphaByte(rest[1]) || rest[1] == '/' || rest[1] == '!' || rest[1] == '?')
+}
+
+// readRawText はscript・style要素の内容を閉じタグの手前まで読み込みます
+func (t *htmlTokenizer) readRawText() htmlToken {
+	closing := []byte("</" + t.rawText)
+	start := t.pos
+	end := len(t.input)
+	for i := t.pos; i+len(closing) <= len(t.input); i++ {
+		if bytes.EqualFold(t.input[i:i+len(closing)], closing) {
+			end = i
+			break
+		}
+	}
+	t.pos = end
+	t.rawText = ""
+	return htmlToken{kind: htmlTextToken, text: string(t.input[start:end])}
+}
+
+// readStartTag は開始タグを読み込みます（> で閉じられていない場合はfalseを返し、位置を戻します）
+func (t *htmlTokenizer) readStartTag() (htmlToken, bool) {
+	start := t.pos
+	t.pos++
+	token := htmlToken{kind: htmlStartTagToken, tag: t.readTagName(), attrs: map[string]string{}}
+	for {
+		t.skipSpaces()
+		if t.pos >= len(t.input) {
+			t.pos = start
+			return htmlToken{}, false
+		}
+		switch t.input[t.pos] {
+		case '>':
+			t.pos++
+			if htmlRawTextTags[token.tag] {
+				t.rawText = token.tag
+			}
+			return token, true
+		case '/':
+			t.pos++
+			continue
+		}
+
+		name, value := t.readAttribute()
+		if _, ok := token.attrs[name]; !ok && name != "" {
+			// 同じ名前の属性は最初のものを使う
+			token.attrs[name] = value
+		}
+	}
+}
+
+// readTagName は要素名を小文字で読み込みます
+func (t *htmlTokenizer) readTagName() string {
+	start := t.pos
+	for t.pos < len(t.input) && !isTagNameEnd(t.input[t.pos]) {
+		t.pos++
+	}
+	return strings.ToLower(string(t.input[start:t.pos]))
+}
+
+// readAttribute は属性の名前（小文字）と値（文字参照を展開した値）を読み込みます
+func (t *htmlTokenizer) readAttribute() (string, string) {
+	start := t.pos
+	// 属性名の最初の文字は = でもよい（不正な書き方だが属性名として扱う）
+	t.pos++
+	for t.pos < len(t.input) && !isTagNameEnd(t.input[t.pos]) && t.input[t.pos] != '=' {
+		t.pos++
+	}
+	name := strings.ToLower(string(t.input[start:t.pos]))
+
+	t.skipSpaces()
+	if t.pos >= len(t.input) || t.input[t.pos] != '=' {
+		return name, ""
+	}
+	t.pos++
+	t.skipSpaces()
+	if t.pos >= len(t.input) {
+		return name, ""
+	}
+
+	var value []byte
+	if quote := t.input[t.pos]; quote == '"' || quote == '\'' {
+		t.pos++
+		end := bytes.IndexByte(t.input[t.pos:], quote)
+		if end < 0 {
+			end = len(t.input) - t.pos
+		}
+		value = t.input[t.pos : t.pos+end]
+		t.pos = min(t.pos+end+1, len(t.input))
+	} else {
+		valueStart := t.pos
+		for t.pos < len(t.input) && !isSpaceByte(t.input[t.pos]) && t.input[t.pos] != '>' {
+			t.pos++
+		}
+		value = t.input[valueStart:t.pos]
+	}
+	return name, html.UnescapeString(string(value))
+}
+
+// skipComment はコメントを読み飛ばします（閉じられていない場合は入力の終わりまで）
+func (t *htmlTokenizer) skipComment() {
+	end := bytes.Index(t.input[t.pos+len("<!--"):], []byte("-->"))
+	if end < 0 {
+		t.pos = len(t.input)
+		return
+	}
+	t.pos += len("<!--") + end + len("-->")
+}
+
+// skipPast は指定した文字の次まで読み飛ばします（見つからない場合は入力の終わりまで）
+func (t *htmlTokenizer) skipPast(c byte) {
+	end := bytes.IndexByte(t.input[t.pos:], c)
+	if end < 0 {
+		t.pos = len(t.input)
+		return
+	}
+	t.pos += end + 1
+}
+
+// skipSpaces は空白を読み飛ばします
+func (t *htmlTokenizer) skipSpaces() {
+	for t.pos < len(t.input) && isHTMLSpace(t.input[t.pos]) {
+		t.pos++
+	}
+}
+
+// isTagNameEnd は要素名・属性名の終わりを表す文字かどうかを判定します
+func isTagNameEnd(c byte) bool {
+	return isHTMLSpace(c) || c == '/' || c == '>'
+}
+
+// isHTMLSpace はHTMLの空白文字かどうかを判定します
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,95 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHTMLTokenizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []htmlToken
+	}{
+		{
+			name:  "開始タグ・テキスト・閉じタグ",
+			input: "<P Class=\"a\">x</P>",
+			expected: []htmlToken{
+				{kind: htmlStartTagToken, tag: "p", attrs: map[string]string{"class": "a"}},
+				{kind: htmlTextToken, text: "x"},
+				{kind: htmlEndTagToken, tag: "p"},
+			},
+		},
+		{
+			name:  "引用符のない属性値と値のない属性",
+			input: "<input type=checkbox checked>",
+			expected: []htmlToken{
+				{kind: htmlStartTagToken, tag: "input", attrs: map[string]string{"type": "checkbox", "checked": ""}},
+			},
+		},
+		{
+			name:  "属性値の<と文字参照",
+			input: "<a title='a<b' href=\"?x=1&amp;y=2\">",
+			expected: []htmlToken{
+				{kind: htmlStartTagToken, tag: "a", attrs: map[string]string{"title": "a<b", "href": "?x=1&y=2"}},
+			},
+		},
+		{
+			name:  "タグにならない<と文字参照",
+			input: "2 &lt; 3 && a < b &nbsp;",
+			expected: []htmlToken{
+				{kind: htmlTextToken, text: "2 < 3 && a < b \u00a0"},
+			},
+		},
+		{
+			name:  "コメントと文書型宣言は出力しない",
+			input: "<!DOCTYPE html><!-- a <b> -->x",
+			expected: []htmlToken{
+				{kind: htmlTextToken, text: "x"},
+			},
+		},
+		{
+			name:  "scriptの内容はタグとして解釈しない",
+			input: "<script>if (a<b) {}</script>",
+			expected: []htmlToken{
+				{kind: htmlStartTagToken, tag: "script", attrs: map[string]string{}},
+				{kind: htmlTextToken, text: "if (a<b) {}"},
+				{kind: htmlEndTagToken, tag: "script"},
+			},
+		},
+		{
+			name:  "名前空間付きの要素と空要素の/",
+			input: "<o:p></o:p><br/>",
+			expected: []htmlToken{
+				{kind: htmlStartTagToken, tag: "o:p", attrs: map[string]string{}},
+				{kind: htmlEndTagToken, tag: "o:p"},
+				{kind: htmlStartTagToken, tag: "br", attrs: map[string]string{}},
+			},
+		},
+		{
+			name:  "閉じられていないタグはテキスト",
+			input: "a <b",
+			expected: []htmlToken{
+				{kind: htmlTextToken, text: "a "},
+				{kind: htmlTextToken, text: "<b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokens []htmlToken
+			tokenizer := newHTMLTokenizer([]byte(tt.input))
+			for {
+				token, ok := tokenizer.next()
+				if !ok {
+					break
+				}
+				tokens = append(tokens, token)
+			}
+			if !reflect.DeepEqual(tokens, tt.expected) {
+				t.Errorf("期待値: %+v, 実際の値: %+v", tt.expected, tokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// AutoLinkStyle は自動リンク（<https://example.com> やLinkifyで検出したURL）の出力形式です
+type AutoLinkStyle int
+
+const (
+	// AutoLinkBare はURLをそのまま出力します（BacklogはURLを自動でリンクにします）
+	AutoLinkBare AutoLinkStyle = iota
+	// AutoLinkBracket は [[url:url]] 形式のリンクとして出力します
+	AutoLinkBracket
+)
+
+// ParseAutoLinkStyle は文字列から自動リンクの出力形式を取得します
+func ParseAutoLinkStyle(s string) (AutoLinkStyle, error) {
+	switch strings.ToLower(s) {
+	case "", "bare":
+		return AutoLinkBare, nil
+	case "bracket":
+		return AutoLinkBracket, nil
+	}
+	return AutoLinkBare, fmt.Errorf("unknown autolink style: %q", s)
+}
+
+// LinkResolver はMarkdownのリンク先をBacklog上で有効なリンク先に解決します
+type LinkResolver interface {
+	ResolveLink(destination string) LinkTarget
+}
+
+// LinkTarget はリンク先の解決結果を表します
+type LinkTarget struct {
+	// URL はリンク先のURLです
+	URL string
+	// WikiPage が空でない場合はBacklogのWikiページへのリンクとして出力します
+	WikiPage string
+}
+
+// LinkResolverFunc は関数をLinkResolverとして扱うためのアダプタです
+type LinkResolverFunc func(destination string) LinkTarget
+
+// ResolveLink はfを呼び出します
+func (f LinkResolverFunc) ResolveLink(destination string) LinkTarget {
+	return f(destination)
+}
+
+// PathLinkResolver は相対リンクをWikiページ・Gitリポジトリ・基準URLの順に解決します
+type PathLinkResolver struct {
+	// BaseURL は相対リンクを解決する基準URLです（例: https://example.com/docs/guide.md）
+	BaseURL string
+	// DocumentDir は変換対象文書のリポジトリルートからの相対ディレクトリです
+	DocumentDir string
+	// WikiPages はリポジトリルートからの.mdパスとBacklogのWikiページ名の対応表です
+	WikiPages map[string]string
+	// GitBlobURL はBacklog Gitビューアのファイル表示URLの接頭辞です
+	// （例: https://example.backlog.com/git/PROJ/repo/blob/main）
+	GitBlobURL string
+}
+
+// ResolveLink は相対リンクを解決します。絶対URLとページ内アンカーはそのまま返します
+func (r *PathLinkResolver) ResolveLink(destination string) LinkTarget {
+	if !isRelativeLink(destination) {
+		return LinkTarget{URL: destination}
+	}
+
+	// パス部分とクエリ・アンカー部分を分離
+	linkPath, suffix := splitLinkSuffix(destination)
+	repoPath := r.repositoryPath(linkPath)
+
+	// .mdファイルへのリンクはWikiページを優先（Wikiリンクはアンカーを持てないため除去）
+	if isMarkdownPath(repoPath) {
+		if page, ok := r.WikiPages[repoPath]; ok {
+			return LinkTarget{WikiPage: page}
+		}
+	}
+
+	if r.GitBlobURL != "" {
+		return LinkTarget{URL: strings.TrimSuffix(r.GitBlobURL, "/") + "/" + repoPath + suffix}
+	}
+
+	if r.BaseURL != "" {
+		base, err := url.Parse(r.BaseURL)
+		ref, refErr := url.Parse(destination)
+		if err == nil && refErr == nil {
+			return LinkTarget{URL: base.ResolveReference(ref).String()}
+		}
+	}
+
+	return LinkTarget{URL: destination}
+}
+
+// repositoryPath はリンクのパスをリポジトリルートからの相対パスに変換します
+func (r *PathLinkResolver) repositoryPath(linkPath string) string {
+	if strings.HasPrefix(linkPath, "/") {
+		return strings.TrimPrefix(path.Clean(linkPath), "/")
+	}
+	return strings.TrimPrefix(path.Clean(path.Join(r.DocumentDir, linkPath)), "/")
+}
+
+// linkSpec はリンクの出力に必要な情報です（LinkとAutoLinkで共通）
+type linkSpec struct {
+	text        string
+	destination string
+	title       string
+	auto        bool
+}
+
+// newLinkSpec はLinkまたはAutoLinkノードからリンクの情報を取り出します
+// リンクテキストに含まれるインラインコードや画像は、その内容や代替テキストを使います
+func newLinkSpec(node ast.Node, source []byte) linkSpec {
+	switch node := node.(type) {
+	case *ast.Link:
+		return linkSpec{text: plainText(node, source), destination: string(node.Destination), title: string(node.Title)}
+	case *ast.AutoLink:
+		spec := linkSpec{text: string(node.Label(source)), destination: string(node.URL(source)), auto: true}
+		if node.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(strings.ToLower(spec.destination), "mailto:") {
+			spec.destination = "mailto:" + spec.destination
+		}
+		return spec
+	}
+	return linkSpec{}
+}
+
+// isBareURL は自動リンクをそのまま出力してもBacklogがリンクにするかどうかを判定します
+// Linkifyで検出した www.example.com のようなスキームのないURLはリンクにならないため、リンク記法で出力します
+func (s linkSpec) isBareURL() bool {
+	return strings.HasPrefix(s.destination, "mailto:") || strings.Contains(s.text, "://")
+}
+
+// resolveLink はリゾルバが設定されていればリンク先を解決します
+func resolveLink(resolver LinkResolver, destination string) LinkTarget {
+	if resolver == nil {
+		return LinkTarget{URL: destination}
+	}
+	return resolver.ResolveLink(destination)
+}
+
+// isRelativeLink はリンク先が解決対象の相対リンクかどうかを判定します
+func isRelativeLink(destination string) bool {
+	if destination == "" || strings.HasPrefix(destination, "#") || strings.HasPrefix(destination, "//") {
+		return false
+	}
+	u, err := url.Parse(destination)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == ""
+}
+
+// splitLinkSuffix はリンク先をパスとクエリ・アンカー部分に分割します
+func splitLinkSuffix(destination string) (string, string) {
+	if i := strings.IndexAny(destination, "?#"); i >= 0 {
+		return destination[:i], destination[i:]
+	}
+	return destination, ""
+}
+
+// isMarkdownPath はパスがMarkdownファイルかどうかを判定します
+func isMarkdownPath(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".md" || ext == ".markdown"
+}
@@ -0,0 +1,252 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestPathLinkResolver(t *testing.T) {
+	resolver := &PathLinkResolver{
+		DocumentDir: "docs/guide",
+		WikiPages: map[string]string{
+			"docs/design/api.md": "設計/API",
+		},
+		GitBlobURL: "https://example.backlog.com/git/PROJ/repo/blob/main/",
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		expected    LinkTarget
+	}{
+		{
+			name:        "絶対URLはそのまま",
+			destination: "https://example.com/a.md",
+			expected:    LinkTarget{URL: "https://example.com/a.md"},
+		},
+		{
+			name:        "ページ内アンカーはそのまま",
+			destination: "#usage",
+			expected:    LinkTarget{URL: "#usage"},
+		},
+		{
+			name:        "メールアドレスはそのまま",
+			destination: "mailto:user@example.com",
+			expected:    LinkTarget{URL: "mailto:user@example.com"},
+		},
+		{
+			name:        "Wikiページへの対応付け（アンカーは除去）",
+			destination: "../design/api.md#auth",
+			expected:    LinkTarget{WikiPage: "設計/API"},
+		},
+		{
+			name:        "リポジトリルートからのパス",
+			destination: "/docs/design/api.md",
+			expected:    LinkTarget{WikiPage: "設計/API"},
+		},
+		{
+			name:        "対応表にない.mdはGitビューアへ（アンカー保持）",
+			destination: "./setup.md#install",
+			expected:    LinkTarget{URL: "https://example.backlog.com/git/PROJ/repo/blob/main/docs/guide/setup.md#install"},
+		},
+		{
+			name:        "画像などのファイルもGitビューアへ",
+			destination: "../img/flow.png",
+			expected:    LinkTarget{URL: "https://example.backlog.com/git/PROJ/repo/blob/main/docs/img/flow.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := resolver.ResolveLink(tt.destination)
+			if result != tt.expected {
+				t.Errorf("期待値: %+v, 実際の値: %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPathLinkResolverBaseURL(t *testing.T) {
+	resolver := &PathLinkResolver{BaseURL: "https://example.com/docs/guide/index.md"}
+
+	result := resolver.ResolveLink("../design/api.md#auth")
+	expected := LinkTarget{URL: "https://example.com/docs/design/api.md#auth"}
+	if result != expected {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, result)
+	}
+}
+
+func TestConvertWithLinkResolver(t *testing.T) {
+	resolver := &PathLinkResolver{
+		WikiPages: map[string]string{
+			"api.md": "API",
+		},
+		BaseURL: "https://example.com/docs/",
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Wikiページリンク（表示名付き）",
+			input:    "[API仕様](api.md)",
+			expected: "[[API仕様>API]]",
+		},
+		{
+			name:     "Wikiページリンク（表示名がページ名と同じ）",
+			input:    "[API](api.md#auth)",
+			expected: "[[API]]",
+		},
+		{
+			name:     "相対リンクを基準URLで解決",
+			input:    "[手順](setup.md)",
+			expected: "[[手順:https://example.com/docs/setup.md]]",
+		},
+		{
+			name:     "相対画像を基準URLで解決",
+			input:    "![図](img/flow.png)",
+			expected: "![図](https://example.com/docs/img/flow.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithLinkResolver(resolver))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "自動リンクはそのまま",
+			input:    "See <https://example.com>.",
+			expected: "See https://example.com.",
+		},
+		{
+			name:     "自動リンクをリンク記法で出力",
+			input:    "See <https://example.com>.",
+			opts:     []Option{WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "See [[https://example.com:https://example.com]].",
+		},
+		{
+			name:     "メールアドレスの自動リンク",
+			input:    "<dev@example.com>",
+			opts:     []Option{WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "[[dev@example.com:mailto:dev@example.com]]",
+		},
+		{
+			name:     "Linkifyで検出したURL",
+			input:    "www.example.com",
+			opts:     []Option{WithLinkify(), WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "[[www.example.com:http://www.example.com]]",
+		},
+		{
+			name:     "リンクのタイトル",
+			input:    "[Docs](https://example.com \"公式ドキュメント\")",
+			expected: "[[Docs:https://example.com]] (公式ドキュメント)",
+		},
+		{
+			name:     "参照形式のリンクのタイトル",
+			input:    "[Docs][docs]\n\n[docs]: https://example.com 'Guide'",
+			expected: "[[Docs:https://example.com]] (Guide)",
+		},
+		{
+			name:     "テキストが空のリンクはタイトルを使用",
+			input:    "[](https://example.com \"Guide\")",
+			expected: "[[Guide:https://example.com]]",
+		},
+		{
+			name:     "テキストもタイトルもないリンクはURLを使用",
+			input:    "[](https://example.com)",
+			expected: "[[https://example.com:https://example.com]]",
+		},
+		{
+			name:     "インラインコードを含むリンク",
+			input:    "[`go test`の使い方](https://example.com)",
+			expected: "[[go testの使い方:https://example.com]]",
+		},
+		{
+			name:     "見出しの自動リンク",
+			input:    "# <https://example.com>",
+			expected: "* https://example.com",
+		},
+		{
+			name:     "見出しの自動リンクをリンク記法で出力",
+			input:    "# <https://example.com>",
+			opts:     []Option{WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "* [[https://example.com:https://example.com]]",
+		},
+		{
+			name:     "引用の自動リンク",
+			input:    "> see <https://example.com>",
+			expected: "> see https://example.com",
+		},
+		{
+			name:     "表のセルの自動リンクをリンク記法で出力",
+			input:    "| url |\n| --- |\n| <https://example.com> |",
+			opts:     []Option{WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "|*url|\n|[[https://example.com:https://example.com]]|",
+		},
+		{
+			name:     "画像を含むリンク",
+			input:    "[![CI](https://example.com/badge.svg)](https://example.com/ci)",
+			expected: "[[CI:https://example.com/ci]]",
+		},
+		{
+			name:     "Markdown出力の自動リンク",
+			input:    "<https://example.com> <dev@example.com>",
+			opts:     []Option{WithFormat(FormatMarkdown), WithAutoLinkStyle(AutoLinkBracket)},
+			expected: "[https://example.com](https://example.com) [dev@example.com](mailto:dev@example.com)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseAutoLinkStyle(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected AutoLinkStyle
+		wantErr  bool
+	}{
+		{input: "", expected: AutoLinkBare},
+		{input: "bare", expected: AutoLinkBare},
+		{input: "Bracket", expected: AutoLinkBracket},
+		{input: "html", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseAutoLinkStyle(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("エラーの有無が期待と異なります: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %v, 実際の値: %v", tt.expected, result)
+			}
+		})
+	}
+}
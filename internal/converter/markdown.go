@@ -0,0 +1,503 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	gast "github.com/yuin/goldmark/extension/ast"
+)
+
+// Format は変換結果の出力形式です
+type Format int
+
+const (
+	// FormatBacklog はBacklog記法で出力します
+	FormatBacklog Format = iota
+	// FormatMarkdown はBacklogのMarkdownモードが表示できるMarkdownに正規化して出力します
+	FormatMarkdown
+)
+
+// ParseFormat は文字列から出力形式を取得します
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "backlog":
+		return FormatBacklog, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	}
+	return FormatBacklog, fmt.Errorf("unknown format: %q", s)
+}
+
+// markdownRenderer はASTをBacklogのMarkdownモードが表示できるMarkdownとして出力する組み込みのNodeRendererです
+type markdownRenderer struct {
+	cfg *config
+	// footnotes は番号と脚注の対応です（脚注を括弧書きで展開する場合のみ）
+	footnotes map[int]*gast.Footnote
+	// listNumbers は処理中のリストごとの次の項目番号です（ネストしたリストの分だけ積みます）
+	listNumbers []int
+}
+
+// RegisterFuncs はMarkdownの出力関数を登録します
+func (r *markdownRenderer) RegisterFuncs(reg NodeRendererFuncRegisterer) {
+	// ブロック要素
+	reg.Register(ast.KindHeading, markdownBlock(r.renderHeading))
+	reg.Register(ast.KindParagraph, markdownBlock(r.renderParagraph))
+	reg.Register(ast.KindTextBlock, markdownBlock(r.renderParagraph))
+	reg.Register(ast.KindThematicBreak, markdownBlock(r.renderThematicBreak))
+	reg.Register(ast.KindFencedCodeBlock, markdownBlock(r.renderFencedCodeBlock))
+	reg.Register(ast.KindCodeBlock, markdownBlock(r.renderCodeBlock))
+	reg.Register(ast.KindBlockquote, markdownBlock(r.renderBlockquote))
+	reg.Register(ast.KindList, markdownBlock(r.renderList))
+	reg.Register(ast.KindListItem, markdownBlock(r.renderListItem))
+	reg.Register(gast.KindTable, markdownBlock(r.renderTable))
+	reg.Register(gast.KindTableHeader, r.renderTableHeader)
+	reg.Register(gast.KindTableRow, r.renderTableRow)
+	reg.Register(gast.KindTableCell, r.renderTableCell)
+	reg.Register(ast.KindHTMLBlock, markdownBlock(r.renderHTMLBlock))
+	reg.Register(gast.KindFootnoteList, markdownBlock(r.renderFootnoteList))
+	reg.Register(gast.KindFootnote, r.renderFootnote)
+	reg.Register(gast.KindDefinitionList, markdownBlock(r.renderDefinitionList))
+	reg.Register(gast.KindDefinitionTerm, markdownBlock(r.renderDefinitionTerm))
+	reg.Register(gast.KindDefinitionDescription, r.renderDefinitionDescription)
+
+	// インライン要素
+	reg.Register(gast.KindTaskCheckBox, r.renderTaskCheckBox)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindText, r.renderText)
+	reg.Register(ast.KindString, r.renderString)
+	reg.Register(ast.KindEmphasis, r.renderEmphasis)
+	reg.Register(gast.KindStrikethrough, r.renderStrikethrough)
+	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
+	reg.Register(ast.KindLink, r.renderLink)
+	reg.Register(ast.KindImage, r.renderImage)
+	reg.Register(ast.KindAutoLink, r.renderLink)
+	reg.Register(KindMention, r.renderMention)
+	reg.Register(KindEmoji, r.renderEmoji)
+	reg.Register(gast.KindFootnoteLink, r.renderFootnoteLink)
+	reg.Register(gast.KindFootnoteBacklink, r.renderFootnoteBacklink)
+
+	reg.Register(ast.KindDocument, r.renderDocument)
+}
+
+func (r *markdownRenderer) renderDocument(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering && r.cfg.footnotes == FootnoteInline {
+		r.footnotes = detachFootnotes(n)
+	}
+	return ast.WalkContinue, nil
+}
+
+// markdownBlock はブロック要素の前に必要な空行を出力するよう出力関数を包みます
+func markdownBlock(fn NodeRendererFunc) NodeRendererFunc {
+	return func(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && startsNewBlock(n) {
+			w.blankLine()
+		}
+		return fn(w, source, n, entering)
+	}
+}
+
+func (r *markdownRenderer) renderHeading(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString(strings.Repeat("#", shiftHeadingLevel(n.(*ast.Heading).Level, r.cfg)) + " ")
+	} else {
+		w.endLine()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderParagraph(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		w.endLine()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderThematicBreak(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString("---\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderFencedCodeBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*ast.FencedCodeBlock)
+		output := r.cfg.codeBlockHook.handle(w, node, source)
+		if output != nil && output.Caption != "" {
+			w.WriteString("**" + output.Caption + "**\n")
+		}
+		if output != nil && output.Image != "" {
+			alt := output.Caption
+			if alt == "" {
+				alt = string(node.Language(source))
+			}
+			w.WriteString("![" + alt + "](" + output.Image + ")\n")
+		} else {
+			info := r.cfg.codeBlockInfo(node, source)
+			if output == nil && r.cfg.codeTitle && info.title != "" {
+				w.WriteString("**" + info.title + "**\n")
+			}
+			writeMarkdownCodeBlock(w, node, info.language, source)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderCodeBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		// インデントによるコードブロックはフェンスに統一する
+		writeMarkdownCodeBlock(w, n, "", source)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderBlockquote(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.pushPrefix("> ", "> ")
+	} else {
+		w.popPrefix()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderList(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		r.listNumbers = append(r.listNumbers, n.(*ast.List).Start)
+	} else {
+		r.listNumbers = r.listNumbers[:len(r.listNumbers)-1]
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderListItem(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		marker := "- "
+		if list, ok := n.Parent().(*ast.List); ok && list.IsOrdered() && len(r.listNumbers) > 0 {
+			number := &r.listNumbers[len(r.listNumbers)-1]
+			marker = markdownListMarker(*number)
+			*number++
+		}
+		w.pushPrefix(marker, strings.Repeat(" ", len(marker)))
+	} else {
+		w.endLine()
+		w.popPrefix()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderTable(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		for _, alignment := range n.(*gast.Table).Alignments {
+			if alignment != gast.AlignNone {
+				w.Warn(n, "table column alignment is not supported and was removed")
+				break
+			}
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderTableHeader(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString("|")
+	} else {
+		w.WriteString("\n|" + strings.Repeat(" --- |", n.ChildCount()) + "\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderTableRow(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString("|")
+	} else {
+		w.WriteString("\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderTableCell(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString(" ")
+	} else {
+		w.WriteString(" |")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderHTMLBlock(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		if isTOCHTMLBlock(n.(*ast.HTMLBlock), source) {
+			// BacklogのMarkdownは [toc] で目次を表示する
+			w.WriteString("[toc]\n")
+		} else {
+			w.Warn(n, "raw HTML is not supported and was removed")
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderTaskCheckBox(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	// BacklogのMarkdownはタスクリストを表示できないため記号に置き換える
+	if entering {
+		if n.(*gast.TaskCheckBox).IsChecked {
+			w.WriteString("☑ ")
+		} else {
+			w.WriteString("☐ ")
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderRawHTML(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.Warn(n, "inline HTML is not supported and was removed")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderText(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*ast.Text)
+		w.Write(node.Segment.Value(source))
+		if node.HardLineBreak() {
+			w.WriteString("  \n")
+		} else if node.SoftLineBreak() {
+			w.WriteString("\n")
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderString(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.Write(n.(*ast.String).Value)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderEmphasis(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	w.WriteString(strings.Repeat("*", n.(*ast.Emphasis).Level))
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderStrikethrough(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	w.WriteString("~~")
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderCodeSpan(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeMarkdownCodeSpan(w, n.(*ast.CodeSpan), source)
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderLink(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	// 自動リンクは子ノードを持たないため、入口でまとめて出力する
+	if node, ok := n.(*ast.AutoLink); ok {
+		if entering {
+			spec := newLinkSpec(node, source)
+			if r.cfg.autoLinkStyle == AutoLinkBare {
+				w.WriteString(spec.text)
+			} else {
+				w.WriteString("[" + spec.text + "](" + spec.destination + ")")
+			}
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if entering {
+		w.WriteString("[")
+	} else {
+		node := n.(*ast.Link)
+		target := resolveLink(r.cfg.linkResolver, string(node.Destination))
+		if target.WikiPage != "" {
+			target.URL = target.WikiPage
+		}
+		w.WriteString("](" + target.URL + markdownLinkTitle(node.Title) + ")")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderImage(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*ast.Image)
+		destination := string(node.Destination)
+		if target := resolveLink(r.cfg.linkResolver, destination); target.URL != "" {
+			destination = target.URL
+		}
+		w.WriteString("![" + plainText(node, source) + "](" + destination + markdownLinkTitle(node.Title) + ")")
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderMention(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeMention(w, n.(*Mention), r.cfg.users)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderEmoji(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		writeEmoji(w, n.(*Emoji))
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderDefinitionList(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderDefinitionTerm(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString("**" + plainText(n, source) + "**\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *markdownRenderer) renderDefinitionDescription(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	// BacklogのMarkdownには定義リストがないため、説明を用語の下のリスト項目として出力する
+	if entering {
+		w.pushPrefix("- ", "  ")
+	} else {
+		w.endLine()
+		w.popPrefix()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderFootnoteList(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		w.WriteString("**" + footnoteSectionTitle + "**\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderFootnote(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		marker := footnoteMarker(n.(*gast.Footnote).Index) + " "
+		w.pushPrefix(marker, strings.Repeat(" ", len(marker)))
+	} else {
+		w.endLine()
+		w.popPrefix()
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderFootnoteLink(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		node := n.(*gast.FootnoteLink)
+		if r.cfg.footnotes == FootnoteInline {
+			writeInlineFootnote(w, r.footnotes[node.Index], source)
+		} else {
+			w.WriteString(footnoteMarker(node.Index))
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *markdownRenderer) renderFootnoteBacklink(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	return ast.WalkSkipChildren, nil
+}
+
+// startsNewBlock はブロックの前に空行が必要かどうかを判定します
+func startsNewBlock(node ast.Node) bool {
+	if node.PreviousSibling() == nil {
+		return false
+	}
+	// 詰めたリストの項目同士・項目内の要素同士は空行を挟まない
+	switch parent := node.Parent().(type) {
+	case *ast.List:
+		return !parent.IsTight
+	case *ast.ListItem:
+		if list, ok := parent.Parent().(*ast.List); ok {
+			return !list.IsTight
+		}
+	}
+	switch node.(type) {
+	case *gast.TableHeader, *gast.TableRow, *gast.TableCell:
+		return false
+	}
+	return true
+}
+
+// markdownListMarker は番号付きリストの項目のマーカーを返します
+func markdownListMarker(number int) string {
+	return strconv.Itoa(number) + ". "
+}
+
+// writeMarkdownCodeBlock はコードブロックをフェンス付きで出力します
+func writeMarkdownCodeBlock(w *Writer, node ast.Node, lang string, source []byte) {
+	var content bytes.Buffer
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		content.Write(line.Value(source))
+	}
+
+	fence := codeFence(content.String(), "```")
+	w.WriteString(fence + lang + "\n")
+	w.WriteString(content.String())
+	w.endLine()
+	w.WriteString(fence + "\n")
+}
+
+// writeMarkdownCodeSpan はインラインコードを内容に含まれない長さのバッククォートで囲んで出力します
+func writeMarkdownCodeSpan(w *Writer, codeSpan *ast.CodeSpan, source []byte) {
+	w.WriteString(markdownCodeSpan(plainText(codeSpan, source)))
+}
+
+// markdownCodeSpan は内容をインラインコードのMarkdownにします
+func markdownCodeSpan(content string) string {
+	fence := codeFence(content, "`")
+	if strings.HasPrefix(content, "`") || strings.HasSuffix(content, "`") {
+		content = " " + content + " "
+	}
+	return fence + content + fence
+}
+
+// codeFence は内容に含まれない長さになるまでフェンスを伸ばして返します
+func codeFence(content, fence string) string {
+	for strings.Contains(content, fence) {
+		fence += fence[:1]
+	}
+	return fence
+}
+
+// markdownLinkTitle はリンクのタイトル部分を返します
+func markdownLinkTitle(title []byte) string {
+	if len(title) == 0 {
+		return ""
+	}
+	return " " + strconv.Quote(string(title))
+}
+
+// plainText はノード配下のテキストを装飾なしで連結します
+func plainText(node ast.Node, source []byte) string {
+	var text strings.Builder
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch child := n.(type) {
+		case *ast.Text:
+			text.Write(child.Segment.Value(source))
+		case *ast.String:
+			text.Write(child.Value)
+		case *ast.AutoLink:
+			text.Write(child.Label(source))
+		case *Mention:
+			text.WriteString("@" + child.Handle)
+		case *Emoji:
+			text.WriteString(child.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return text.String()
+}
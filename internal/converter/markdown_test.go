@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "基本的な記法はそのまま",
+			input:    "# 見出し\n\n**太字**と*斜体*と~~打ち消し~~と`コード`",
+			expected: "# 見出し\n\n**太字**と*斜体*と~~打ち消し~~と`コード`",
+		},
+		{
+			name:     "タスクリストを記号に置き換え",
+			input:    "- [ ] 未完了\n- [x] 完了",
+			expected: "- ☐ 未完了\n- ☑ 完了",
+		},
+		{
+			name:     "ネストしたリストと番号付きリスト",
+			input:    "* レベル1\n    * レベル2\n\n3. 三\n4. 四",
+			expected: "- レベル1\n  - レベル2\n\n3. 三\n4. 四",
+		},
+		{
+			name:     "テーブルの配置指定を除去",
+			input:    "| 左 | 右 |\n|:---|---:|\n| a | b |",
+			expected: "| 左 | 右 |\n| --- | --- |\n| a | b |",
+		},
+		{
+			name:     "HTMLを除去",
+			input:    "前\n\n<div>ブロック</div>\n\n後ろ <b>太字</b>",
+			expected: "前\n\n後ろ 太字",
+		},
+		{
+			name:     "目次マーカーを[toc]に置き換え",
+			input:    "<!-- toc -->\n\n# 見出し",
+			expected: "[toc]\n\n# 見出し",
+		},
+		{
+			name:     "インデントのコードブロックをフェンスに統一",
+			input:    "コード:\n\n    echo hello",
+			expected: "コード:\n\n```\necho hello\n```",
+		},
+		{
+			name:     "引用内のリストとコード",
+			input:    "> 引用\n>\n> - 項目\n>\n> ```sh\n> ls\n> ```",
+			expected: "> 引用\n>\n> - 項目\n>\n> ```bash\n> ls\n> ```",
+		},
+		{
+			name:     "リンクと画像",
+			input:    "[リンク](http://example.com \"タイトル\") ![図](img.png)",
+			expected: "[リンク](http://example.com \"タイトル\") ![図](img.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithFormat(FormatMarkdown))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertToMarkdownWarnings(t *testing.T) {
+	result, err := ConvertDetailed("| a |\n|:-:|\n| b |\n\n<div>x</div>", WithFormat(FormatMarkdown))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := []string{
+		"line 1: table column alignment is not supported and was removed",
+		"line 5: raw HTML is not supported and was removed",
+	}
+	if len(result.Warnings) != len(expected) {
+		t.Fatalf("期待値: %q, 実際の値: %v", expected, result.Warnings)
+	}
+	for i, warning := range result.Warnings {
+		if warning.String() != expected[i] {
+			t.Errorf("期待値: %q, 実際の値: %q", expected[i], warning.String())
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, expected := range map[string]Format{"": FormatBacklog, "backlog": FormatBacklog, "markdown": FormatMarkdown, "MD": FormatMarkdown} {
+		format, err := ParseFormat(input)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %v, %v; 期待値: %v", input, format, err, expected)
+		}
+	}
+
+	if _, err := ParseFormat("html"); err == nil {
+		t.Errorf("期待されたエラーが発生しませんでした")
+	}
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// KindMention はメンションノードの種類です
+var KindMention = ast.NewNodeKind("Mention")
+
+// Mention は @username 形式のメンションを表すインラインノードです
+type Mention struct {
+	ast.BaseInline
+	// Handle は @ を除いたユーザー名です
+	Handle string
+}
+
+// Kind はノードの種類を返します
+func (n *Mention) Kind() ast.NodeKind {
+	return KindMention
+}
+
+// Dump はデバッグ用にノードの内容を出力します
+func (n *Mention) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Handle": n.Handle}, nil)
+}
+
+// mentionParser は @username をMentionノードとして解析するインラインパーサーです
+type mentionParser struct{}
+
+// Trigger はパーサーを起動する文字を返します
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+// Parse は @username を解析します
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	// メールアドレスなど、直前が英数字の場合はメンションとしない
+	if isHandleChar(block.PrecendingCharacter()) {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	i := 1
+	for i < len(line) && isHandleChar(rune(line[i])) {
+		i++
+	}
+	if i == 1 {
+		return nil
+	}
+
+	block.Advance(i)
+	return &Mention{Handle: string(line[1:i])}
+}
+
+// isHandleChar はユーザー名に使える文字かどうかを判定します
+func isHandleChar(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_')
+}
+
+// writeMention はメンションをユーザー対応表に従って出力します（対応がない場合はそのまま）
+func writeMention(w *Writer, mention *Mention, users map[string]string) {
+	w.WriteString("@")
+	if user, ok := users[mention.Handle]; ok {
+		w.WriteString(user)
+		return
+	}
+	w.WriteString(mention.Handle)
+}
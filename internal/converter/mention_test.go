@@ -0,0 +1,119 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertWithMentions(t *testing.T) {
+	users := map[string]string{
+		"octocat": "yamada",
+		"j-doe":   "doe.john",
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "対応表にあるメンションを変換",
+			input:    "@octocat さん確認お願いします",
+			expected: "@yamada さん確認お願いします",
+		},
+		{
+			name:     "ハイフンを含むユーザー名",
+			input:    "cc: @j-doe, @octocat",
+			expected: "cc: @doe.john, @yamada",
+		},
+		{
+			name:     "対応表にないメンションはそのまま",
+			input:    "@unknown さん",
+			expected: "@unknown さん",
+		},
+		{
+			name:     "メールアドレスはメンションとしない",
+			input:    "user@octocat",
+			expected: "user@octocat",
+		},
+		{
+			name:     "インラインコード内は変換しない",
+			input:    "`@octocat`",
+			expected: "{code}@octocat{/code}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithMentions(users))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertMentionsAndEmojiInContainers(t *testing.T) {
+	users := map[string]string{"alice": "suzuki"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "見出し",
+			input:    "# Release :rocket: by @alice",
+			expected: "* Release 🚀 by @suzuki",
+		},
+		{
+			name:     "太字",
+			input:    "**hi @alice :tada:**",
+			expected: "''hi @suzuki 🎉''",
+		},
+		{
+			name:     "打ち消し線",
+			input:    "~~@alice :tada:~~",
+			expected: "%%@suzuki 🎉%%",
+		},
+		{
+			name:     "引用",
+			input:    "> thanks @alice :+1:\n> again",
+			expected: "> thanks @suzuki 👍\n> again",
+		},
+		{
+			name:     "ネストした引用",
+			input:    "> outer\n>\n> > inner @alice :+1:",
+			expected: "> outer\n> > inner @suzuki 👍",
+		},
+		{
+			name:     "表のセル",
+			input:    "| who | mood |\n| --- | --- |\n| @alice | :tada: |",
+			expected: "|*who|*mood|\n|@suzuki|🎉|",
+		},
+		{
+			name:     "リストアイテム",
+			input:    "- done :tada: @alice\n- next",
+			expected: "- done 🎉 @suzuki\n- next",
+		},
+		{
+			name:     "ネストしたリストアイテム",
+			input:    "- parent\n  - child @alice\n- next :tada:",
+			expected: "- parent\n-- child @suzuki\n- next 🎉",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithMentions(users), WithEmoji(nil))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// issueURLPattern はBacklogの課題のURL（https://example.backlog.com/view/PROJ-123）です
+var issueURLPattern = regexp.MustCompile(`/view/([A-Z][A-Z0-9_]*-[0-9]+)(?:[?#].*)?$`)
+
+// Metadata は文書の見出し・リンク・画像の一覧です
+type Metadata struct {
+	Headings []Heading
+	Links    []Link
+	Images   []Image
+}
+
+// Heading は文書中の見出しです
+type Heading struct {
+	// Level はMarkdown上の見出しのレベルです（WithHeadingOffsetは適用しません）
+	Level int
+	Text  string
+	// Line はMarkdown上の行番号（1始まり）です
+	Line int
+}
+
+// Link は文書中のリンクです（自動リンクを含みます）
+type Link struct {
+	Text string
+	// URL はWithLinkResolverで解決したリンク先です
+	URL string
+	// WikiPage はWikiページへのリンクとして解決した場合のページ名です
+	WikiPage string
+	// Issue はリンク先がBacklogの課題の場合の課題キーです
+	Issue string
+	Line  int
+}
+
+// Image は文書中の画像です
+type Image struct {
+	Alt string
+	// URL はWithLinkResolverで解決した画像のURLです
+	URL  string
+	Line int
+}
+
+// ExtractMetadata はMarkdownから見出し・リンク・画像の一覧を取り出します
+// 変換結果と合わせて使う場合は、ConvertDetailedの結果のResult.Metadataを使えば文書を再びパースせずに済みます
+func ExtractMetadata(markdown string, opts ...Option) (*Metadata, error) {
+	cfg := newConfig(opts)
+	source := []byte(markdown)
+	if cfg.input == InputHTML {
+		source = htmlToMarkdown(source)
+	}
+
+	reader := text.NewReader(source)
+	document := newMarkdown(cfg).Parser().Parse(reader)
+	source = reader.Source()
+	metadata, err := collectMetadata(document, source, cfg, &diagnostics{source: source})
+	if err != nil {
+		return nil, err
+	}
+
+	// HTMLから変換したMarkdownの行番号は入力の行とは対応しないため除く
+	if cfg.input == InputHTML {
+		metadata.clearLines()
+	}
+	return metadata, nil
+}
+
+// collectMetadata はパースした文書から見出し・リンク・画像の一覧を取り出します
+func collectMetadata(document ast.Node, source []byte, cfg *config, diag *diagnostics) (*Metadata, error) {
+	metadata := &Metadata{Headings: []Heading{}, Links: []Link{}, Images: []Image{}}
+	err := ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Heading:
+			metadata.Headings = append(metadata.Headings, Heading{Level: node.Level, Text: plainText(node, source), Line: diag.lineOf(node)})
+		case *ast.Link:
+			metadata.Links = append(metadata.Links, newLink(cfg, plainText(node, source), string(node.Destination), diag.lineOf(node)))
+		case *ast.AutoLink:
+			url := string(node.URL(source))
+			metadata.Links = append(metadata.Links, newLink(cfg, url, url, diag.lineOf(node)))
+		case *ast.Image:
+			target := resolveLink(cfg.linkResolver, string(node.Destination))
+			if target.URL == "" {
+				target.URL = string(node.Destination)
+			}
+			metadata.Images = append(metadata.Images, Image{Alt: plainText(node, source), URL: target.URL, Line: diag.lineOf(node)})
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// clearLines は行番号をすべて不明（0）にします
+func (m *Metadata) clearLines() {
+	for i := range m.Headings {
+		m.Headings[i].Line = 0
+	}
+	for i := range m.Links {
+		m.Links[i].Line = 0
+	}
+	for i := range m.Images {
+		m.Images[i].Line = 0
+	}
+}
+
+// newLink はリンク先を解決し、課題へのリンクであれば課題キーを設定したLinkを返します
+func newLink(cfg *config, text, destination string, line int) Link {
+	target := resolveLink(cfg.linkResolver, destination)
+	if target.URL == "" {
+		target.URL = destination
+	}
+	link := Link{Text: text, URL: target.URL, WikiPage: target.WikiPage, Line: line}
+	if m := issueURLPattern.FindStringSubmatch(target.URL); m != nil {
+		link.Issue = m[1]
+	}
+	return link
+}
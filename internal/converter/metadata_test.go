@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	input := "# リリース手順\n\n詳細は [課題](https://example.backlog.com/view/PROJ-12) と [手順書](docs/setup.md) を参照。\n\n## 確認\n\n![構成図](img/arch.png) <https://example.com>\n"
+	resolver := LinkResolverFunc(func(destination string) LinkTarget {
+		if destination == "docs/setup.md" {
+			return LinkTarget{WikiPage: "Setup"}
+		}
+		return LinkTarget{URL: destination}
+	})
+
+	metadata, err := ExtractMetadata(input, WithLinkResolver(resolver))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	expected := &Metadata{
+		Headings: []Heading{
+			{Level: 1, Text: "リリース手順", Line: 1},
+			{Level: 2, Text: "確認", Line: 5},
+		},
+		Links: []Link{
+			{Text: "課題", URL: "https://example.backlog.com/view/PROJ-12", Issue: "PROJ-12", Line: 3},
+			{Text: "手順書", URL: "docs/setup.md", WikiPage: "Setup", Line: 3},
+			{Text: "https://example.com", URL: "https://example.com", Line: 7},
+		},
+		Images: []Image{
+			{Alt: "構成図", URL: "img/arch.png", Line: 7},
+		},
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, metadata)
+	}
+}
+
+func TestExtractMetadataEmpty(t *testing.T) {
+	metadata, err := ExtractMetadata("本文だけ")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(metadata.Headings) != 0 || metadata.Links == nil || metadata.Images == nil {
+		t.Errorf("空の一覧を期待しましたが、実際の値: %+v", metadata)
+	}
+}
+
+func TestConvertDetailedMetadata(t *testing.T) {
+	input := "# 件名\n\n[手順書](docs/setup.md)\n\n## 確認\n"
+
+	result, err := ConvertDetailed(input, WithTitleFromFirstH1())
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	metadata, err := ExtractMetadata(input)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	// 本文から取り除いた件名の見出しも含め、ExtractMetadataと同じ一覧になる
+	if !reflect.DeepEqual(result.Metadata, *metadata) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", *metadata, result.Metadata)
+	}
+}
@@ -0,0 +1,232 @@
+package converter
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/util"
+)
+
+// Option は変換処理の設定を変更する関数です
+type Option func(*config)
+
+// MaxHeadingLevel はBacklog記法で表現できる見出しの最大レベルです
+const MaxHeadingLevel = 6
+
+// config は変換処理の設定を保持します
+type config struct {
+	input         InputFormat
+	format        Format
+	linkResolver  LinkResolver
+	autoLinkStyle AutoLinkStyle
+	tocStyle      TOCStyle
+	footnotes     FootnoteStyle
+
+	definitionList bool
+	typographer    bool
+	linkify        bool
+
+	headingOffset    int
+	maxHeadingLevel  int
+	titleFromFirstH1 bool
+
+	localAttachments bool
+	codeBlockHook    *codeBlockHook
+	languageAliases  map[string]string
+	codeTitle        bool
+
+	users map[string]string
+	emoji map[string]string
+
+	extensions    []goldmark.Extender
+	nodeRenderers util.PrioritizedSlice
+}
+
+// newConfig はオプションを適用した設定を生成します
+func newConfig(opts []Option) *config {
+	cfg := &config{maxHeadingLevel: MaxHeadingLevel}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// validate は組み合わせて使えないオプションを検出します
+func (c *config) validate() error {
+	// Markdown形式の出力ではローカルファイルの参照を #image / #attach マクロに書き換えられない
+	if c.localAttachments && c.format == FormatMarkdown {
+		return errors.New("local attachments are not supported with the markdown format")
+	}
+	return nil
+}
+
+// WithFormat は出力形式を設定します（既定はFormatBacklog）
+func WithFormat(format Format) Option {
+	return func(c *config) {
+		c.format = format
+	}
+}
+
+// WithInputFormat は入力の形式を設定します（既定はInputMarkdown）
+func WithInputFormat(input InputFormat) Option {
+	return func(c *config) {
+		c.input = input
+	}
+}
+
+// WithLinkResolver はリンク・画像のリンク先を解決するLinkResolverを設定します
+func WithLinkResolver(resolver LinkResolver) Option {
+	return func(c *config) {
+		c.linkResolver = resolver
+	}
+}
+
+// WithAutoLinkStyle は自動リンクの出力形式を設定します（既定はAutoLinkBare）
+func WithAutoLinkStyle(style AutoLinkStyle) Option {
+	return func(c *config) {
+		c.autoLinkStyle = style
+	}
+}
+
+// WithTOC は目次マーカー（[TOC] または <!-- toc -->）を指定の形式の目次に置き換えます
+func WithTOC(style TOCStyle) Option {
+	return func(c *config) {
+		c.tocStyle = style
+	}
+}
+
+// WithFootnotes は脚注記法（[^1] と [^1]: ...）を指定の形式で出力します
+func WithFootnotes(style FootnoteStyle) Option {
+	return func(c *config) {
+		c.footnotes = style
+	}
+}
+
+// WithDefinitionList は定義リスト（用語の次の行を ": " で始める記法）を解釈します
+// 用語を太字の行、説明をインデントした行として出力します
+func WithDefinitionList() Option {
+	return func(c *config) {
+		c.definitionList = true
+	}
+}
+
+// WithTypographer は引用符・ダッシュ・三点リーダーを組版用の記号（“”‘’–—…）に置き換えます
+func WithTypographer() Option {
+	return func(c *config) {
+		c.typographer = true
+	}
+}
+
+// WithLinkify は本文中のURLやメールアドレスをリンクとして解釈します（既定では解釈せずテキストのまま出力）
+func WithLinkify() Option {
+	return func(c *config) {
+		c.linkify = true
+	}
+}
+
+// WithHeadingOffset は見出しレベルをoffset分ずらします（例: 1なら # を ** として出力）
+func WithHeadingOffset(offset int) Option {
+	return func(c *config) {
+		c.headingOffset = offset
+	}
+}
+
+// WithMaxHeadingLevel は見出しレベルの上限を設定します（1〜MaxHeadingLevelの範囲に丸めます）
+func WithMaxHeadingLevel(level int) Option {
+	return func(c *config) {
+		c.maxHeadingLevel = min(max(level, 1), MaxHeadingLevel)
+	}
+}
+
+// WithTitleFromFirstH1 は最初のH1を本文から取り除き、Result.Titleとして返します
+func WithTitleFromFirstH1() Option {
+	return func(c *config) {
+		c.titleFromFirstH1 = true
+	}
+}
+
+// WithLocalAttachments はローカルファイルへの参照を #image / #attach マクロで出力し、
+// 参照されたファイルをResult.Attachmentsとして返します
+func WithLocalAttachments() Option {
+	return func(c *config) {
+		c.localAttachments = true
+	}
+}
+
+// WithCodeBlockHook は指定した言語のフェンスコードブロックをフックで変換します
+// 言語を省略した場合はDiagramLanguages（math・mermaid・plantuml）が対象です
+func WithCodeBlockHook(hook CodeBlockHook, languages ...string) Option {
+	return func(c *config) {
+		if len(languages) == 0 {
+			languages = DiagramLanguages
+		}
+		h := &codeBlockHook{hook: hook, languages: make(map[string]bool, len(languages))}
+		for _, language := range languages {
+			h.languages[strings.ToLower(language)] = true
+		}
+		c.codeBlockHook = h
+	}
+}
+
+// WithLanguageAliases はコードブロックの言語名の別名を追加します
+// customで指定した対応はDefaultLanguageAliasesより優先されます
+func WithLanguageAliases(custom map[string]string) Option {
+	return func(c *config) {
+		c.languageAliases = make(map[string]string, len(DefaultLanguageAliases)+len(custom))
+		for alias, language := range DefaultLanguageAliases {
+			c.languageAliases[alias] = language
+		}
+		for alias, language := range custom {
+			c.languageAliases[strings.ToLower(alias)] = language
+		}
+	}
+}
+
+// WithCodeTitle はコードブロックの情報文字列に指定されたタイトル（title="main.go" など）をブロックの上に太字で出力します
+func WithCodeTitle() Option {
+	return func(c *config) {
+		c.codeTitle = true
+	}
+}
+
+// WithMentions は @username をユーザー対応表（GitHubのユーザー名 → BacklogのユーザーID・名前）に従って変換します
+// 対応表にないユーザー名はそのまま出力します
+func WithMentions(users map[string]string) Option {
+	return func(c *config) {
+		c.users = users
+		if c.users == nil {
+			c.users = map[string]string{}
+		}
+	}
+}
+
+// WithEmoji は :shortcode: をUnicodeの絵文字に変換します
+// customで指定した対応はDefaultEmojiより優先されます
+func WithEmoji(custom map[string]string) Option {
+	return func(c *config) {
+		c.emoji = make(map[string]string, len(DefaultEmoji)+len(custom))
+		for shortCode, value := range DefaultEmoji {
+			c.emoji[shortCode] = value
+		}
+		for shortCode, value := range custom {
+			c.emoji[shortCode] = value
+		}
+	}
+}
+
+// WithExtensions はMarkdownのパースにgoldmarkの拡張を追加します
+// 拡張が生成する独自のノードはWithNodeRenderersで出力関数を登録します
+func WithExtensions(extensions ...goldmark.Extender) Option {
+	return func(c *config) {
+		c.extensions = append(c.extensions, extensions...)
+	}
+}
+
+// WithNodeRenderers はNodeRendererを優先度付きで登録します（util.Prioritizedで指定）
+// 優先度の値が組み込みのNodeRenderer（1000）より小さいものは、同じ種類のノードの組み込みの出力関数を上書きします
+func WithNodeRenderers(renderers ...util.PrioritizedValue) Option {
+	return func(c *config) {
+		c.nodeRenderers = append(c.nodeRenderers, renderers...)
+	}
+}
@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SplitByLength は変換結果がlimit文字を超える場合に、ブロックの境界で複数の部分に分割します
+// 各部分の先頭には "(1/3)" の行を付け、この行を含めてlimit文字以内に収めます
+// コードブロックと表の途中では分割せず、できるだけ空行（段落の境界）で分割します
+// limit以内の場合は変換結果をそのまま1つだけ返します
+func SplitByLength(body string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid length limit: %d", limit)
+	}
+	if utf8.RuneCountInString(body) <= limit {
+		return []string{body}, nil
+	}
+
+	blocks := splitBlocks(body)
+	// 見出しの長さは部分の数の桁数で変わるため、桁数が足りなければ分割し直す
+	for digits := 1; ; digits++ {
+		header := 2*digits + len("(/)\n")
+		if limit <= header {
+			return nil, fmt.Errorf("length limit %d is too small for part headers", limit)
+		}
+		parts, err := packBlocks(blocks, limit-header)
+		if err != nil {
+			return nil, err
+		}
+		if len(strconv.Itoa(len(parts))) > digits {
+			continue
+		}
+		for i, part := range parts {
+			parts[i] = fmt.Sprintf("(%d/%d)\n%s", i+1, len(parts), part)
+		}
+		return parts, nil
+	}
+}
+
+// outputBlock は分割できない行のまとまり（コードブロック、表、1行）です
+type outputBlock struct {
+	text string
+	// line は変換結果での開始行（1始まり）です
+	line int
+}
+
+// blank は空行かどうかを判定します（空行は段落の境界です）
+func (b outputBlock) blank() bool {
+	return strings.TrimSpace(b.text) == ""
+}
+
+// splitBlocks は変換結果を分割できない行のまとまりに分けます
+func splitBlocks(body string) []outputBlock {
+	lines := strings.Split(body, "\n")
+	var blocks []outputBlock
+	for i := 0; i < len(lines); {
+		end := i + 1
+		switch line := lines[i]; {
+		case strings.HasPrefix(line, ">{code"):
+			// Backlog記法のコードブロックは {/code}< で終わる行まで
+			for end = i; end < len(lines) && !strings.HasSuffix(lines[end], "{/code}<"); end++ {
+			}
+			end = min(end+1, len(lines))
+		case strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~"):
+			// Markdownモードのコードブロックは同じ記号のフェンスの行まで
+			fence := line[:3]
+			for end = i + 1; end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), fence); end++ {
+			}
+			end = min(end+1, len(lines))
+		case strings.HasPrefix(line, "|"):
+			for end < len(lines) && strings.HasPrefix(lines[end], "|") {
+				end++
+			}
+		}
+		blocks = append(blocks, outputBlock{text: strings.Join(lines[i:end], "\n"), line: i + 1})
+		i = end
+	}
+	return blocks
+}
+
+// packBlocks はブロックをlimit文字以内の部分にまとめます
+// 部分に収まらなくなった場合は、その部分の最後の空行で区切り、空行がなければ直前のブロックで区切ります
+func packBlocks(blocks []outputBlock, limit int) ([]string, error) {
+	var parts []string
+	var current []outputBlock
+	length := 0
+	for _, block := range blocks {
+		if len(current) == 0 && block.blank() {
+			continue
+		}
+		size := utf8.RuneCountInString(block.text)
+		if len(current) > 0 {
+			size++ // 前の行との間の改行
+		}
+		if length+size <= limit {
+			current = append(current, block)
+			length += size
+			continue
+		}
+
+		if len(current) == 0 {
+			return nil, fmt.Errorf("line %d: block of %d characters does not fit in a part (at most %d characters besides the part header)", block.line, size, limit)
+		}
+		if block.blank() {
+			// 空行で区切るので、空行は次の部分に持ち越さない
+			parts = append(parts, joinBlocks(current))
+			current, length = nil, 0
+			continue
+		}
+		cut := len(current)
+		for i := len(current) - 1; i > 0; i-- {
+			if current[i].blank() {
+				cut = i
+				break
+			}
+		}
+		parts = append(parts, joinBlocks(current[:cut]))
+
+		// 最後の空行より後の段落は次の部分に持ち越し、それでも収まらなければ単独の部分にする
+		carried := trimBlankBlocks(current[cut:])
+		if len(carried) > 0 && blocksLength(append(slices.Clone(carried), block)) > limit {
+			parts = append(parts, joinBlocks(carried))
+			carried = nil
+		}
+		current = append(slices.Clone(carried), block)
+		if length = blocksLength(current); length > limit {
+			return nil, fmt.Errorf("line %d: block of %d characters does not fit in a part (at most %d characters besides the part header)", block.line, utf8.RuneCountInString(block.text), limit)
+		}
+	}
+	if len(current) > 0 {
+		parts = append(parts, joinBlocks(current))
+	}
+	return parts, nil
+}
+
+// blocksLength はブロックを改行でつないだテキストの文字数を返します
+func blocksLength(blocks []outputBlock) int {
+	return utf8.RuneCountInString(joinBlocks(blocks))
+}
+
+// trimBlankBlocks は先頭と末尾の空行を取り除きます
+func trimBlankBlocks(blocks []outputBlock) []outputBlock {
+	for len(blocks) > 0 && blocks[0].blank() {
+		blocks = blocks[1:]
+	}
+	for len(blocks) > 0 && blocks[len(blocks)-1].blank() {
+		blocks = blocks[:len(blocks)-1]
+	}
+	return blocks
+}
+
+// joinBlocks はブロックを改行でつないだテキストを返します（前後の空行は除きます）
+func joinBlocks(blocks []outputBlock) string {
+	blocks = trimBlankBlocks(blocks)
+	texts := make([]string, len(blocks))
+	for i, block := range blocks {
+		texts[i] = block.text
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitByLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "制限以内",
+			input:    "短い本文",
+			limit:    10,
+			expected: []string{"短い本文"},
+		},
+		{
+			name:     "段落の境界で分割",
+			input:    "* 見出し\n1行目\n\n2段落目の1行目\n2段落目の2行目",
+			limit:    25,
+			expected: []string{"(1/2)\n* 見出し\n1行目", "(2/2)\n2段落目の1行目\n2段落目の2行目"},
+		},
+		{
+			name:     "空行がなければ行の境界で分割",
+			input:    "ああああああああああ\nいいいいいいいいいい\nうううううううううう",
+			limit:    27,
+			expected: []string{"(1/2)\nああああああああああ\nいいいいいいいいいい", "(2/2)\nうううううううううう"},
+		},
+		{
+			name:     "コードブロックの途中では分割しない",
+			input:    "コードブロックの前にある説明の文章です\n>{code:go}\na := 1\nb := 2\n{/code}<\n後",
+			limit:    42,
+			expected: []string{"(1/2)\nコードブロックの前にある説明の文章です", "(2/2)\n>{code:go}\na := 1\nb := 2\n{/code}<\n後"},
+		},
+		{
+			name:     "表の途中では分割しない",
+			input:    "前の段落前の段落前の段落\n|a|b|h\n|1|2|\n|3|4|",
+			limit:    26,
+			expected: []string{"(1/2)\n前の段落前の段落前の段落", "(2/2)\n|a|b|h\n|1|2|\n|3|4|"},
+		},
+		{
+			name:     "Markdownモードのコードブロック",
+			input:    "前前前前前前前前\n```\nx\n\ny\n```",
+			limit:    18,
+			expected: []string{"(1/2)\n前前前前前前前前", "(2/2)\n```\nx\n\ny\n```"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := SplitByLength(tt.input, tt.limit)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if !reflect.DeepEqual(parts, tt.expected) {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, parts)
+			}
+			for _, part := range parts {
+				if n := utf8.RuneCountInString(part); n > tt.limit {
+					t.Errorf("制限を超えています: %d > %d: %q", n, tt.limit, part)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitByLengthManyParts(t *testing.T) {
+	// 部分が10個以上になると見出しが長くなるため、その分も制限に含める
+	input := strings.Repeat("1234567890\n\n", 12)
+	parts, err := SplitByLength(strings.TrimSuffix(input, "\n\n"), 22)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(parts) != 12 || parts[11] != "(12/12)\n1234567890" {
+		t.Errorf("期待値: 12個の部分, 実際の値: %q", parts)
+	}
+}
+
+func TestSplitByLengthError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		limit    int
+		expected string
+	}{
+		{name: "不正な制限", input: "a", limit: 0, expected: "invalid length limit"},
+		{name: "見出しが入らない", input: "abcdefg", limit: 6, expected: "too small"},
+		{name: "長すぎるコードブロック", input: "前\n>{code}\n0123456789\n{/code}<", limit: 15, expected: "line 2: block of 27 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SplitByLength(tt.input, tt.limit)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("期待値: %q, 実際の値: %v", tt.expected, err)
+			}
+		})
+	}
+}
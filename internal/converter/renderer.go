@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// NodeRendererFunc はノードを出力する関数です（goldmarkのrenderer.NodeRendererFuncに相当）
+type NodeRendererFunc func(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error)
+
+// NodeRendererFuncRegisterer はノードの種類ごとに出力関数を登録します
+type NodeRendererFuncRegisterer interface {
+	Register(kind ast.NodeKind, fn NodeRendererFunc)
+}
+
+// NodeRenderer はノードの種類ごとの出力関数をまとめて登録します（goldmarkのrenderer.NodeRendererに相当）
+type NodeRenderer interface {
+	RegisterFuncs(reg NodeRendererFuncRegisterer)
+}
+
+// builtinRendererPriority は組み込みのNodeRendererの優先度です
+// これより小さい優先度で登録したNodeRendererが組み込みの出力関数を上書きします
+const builtinRendererPriority = 1000
+
+// nodeRendererRegistry はノードの種類と出力関数の対応を保持します
+type nodeRendererRegistry struct {
+	funcs map[ast.NodeKind]NodeRendererFunc
+}
+
+// newNodeRendererRegistry は優先度の低い順にNodeRendererを登録したレジストリを生成します
+func newNodeRendererRegistry(renderers util.PrioritizedSlice) *nodeRendererRegistry {
+	registry := &nodeRendererRegistry{funcs: map[ast.NodeKind]NodeRendererFunc{}}
+
+	// 優先度の値が小さいものほど後に登録し、同じ種類の出力関数を上書きする
+	renderers.Sort()
+	for i := len(renderers) - 1; i >= 0; i-- {
+		if renderer, ok := renderers[i].Value.(NodeRenderer); ok {
+			renderer.RegisterFuncs(registry)
+		}
+	}
+	return registry
+}
+
+// Register はノードの種類に出力関数を登録します
+func (r *nodeRendererRegistry) Register(kind ast.NodeKind, fn NodeRendererFunc) {
+	r.funcs[kind] = fn
+}
+
+// render はASTをウォークし、ノードの種類ごとに登録された出力関数を呼び出します
+// 出力関数が登録されていないノードは子要素のみ処理します
+func (r *nodeRendererRegistry) render(w *Writer, source []byte, document ast.Node) error {
+	return ast.Walk(document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if fn, ok := r.funcs[n.Kind()]; ok && fn != nil {
+			return fn(w, source, n, entering)
+		}
+		return ast.WalkContinue, nil
+	})
+}
@@ -0,0 +1,123 @@
+package converter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// linkOverride はリンクの組み込みの出力関数を上書きするNodeRendererです
+type linkOverride struct{}
+
+func (r *linkOverride) RegisterFuncs(reg NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindLink, func(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			w.WriteString("<" + string(n.(*ast.Link).Destination) + ">")
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// kindBadge は独自拡張のノードの種類です
+var kindBadge = ast.NewNodeKind("Badge")
+
+// badge は {{label}} を表す独自拡張のノードです
+type badge struct {
+	ast.BaseInline
+	label string
+}
+
+func (n *badge) Kind() ast.NodeKind {
+	return kindBadge
+}
+
+func (n *badge) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Label": n.label}, nil)
+}
+
+// badgeParser は {{label}} をbadgeノードとして解析します
+type badgeParser struct{}
+
+func (p *badgeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *badgeParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("{{")) {
+		return nil
+	}
+	end := bytes.Index(line, []byte("}}"))
+	if end < 0 {
+		return nil
+	}
+	block.Advance(end + 2)
+	return &badge{label: string(line[2:end])}
+}
+
+// badgeExtension はbadgeParserを登録するgoldmarkの拡張です
+type badgeExtension struct{}
+
+func (e *badgeExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(&badgeParser{}, 100)))
+}
+
+// badgeRenderer は独自拡張のノードの出力関数を登録します
+type badgeRenderer struct{}
+
+func (r *badgeRenderer) RegisterFuncs(reg NodeRendererFuncRegisterer) {
+	reg.Register(kindBadge, func(w *Writer, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			w.WriteString("&color(red){" + n.(*badge).label + "}")
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+func TestWithNodeRenderersOverridesBuiltin(t *testing.T) {
+	result, err := Convert("[リンク](http://example.com) と **太字**", WithNodeRenderers(util.Prioritized(&linkOverride{}, 100)))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := "<http://example.com> と ''太字''"
+	if result != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, result)
+	}
+}
+
+func TestWithNodeRenderersLowerPriorityDoesNotOverride(t *testing.T) {
+	result, err := Convert("[リンク](http://example.com)", WithNodeRenderers(util.Prioritized(&linkOverride{}, 2000)))
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生しました: %v", err)
+	}
+
+	expected := "[[リンク:http://example.com]]"
+	if result != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, result)
+	}
+}
+
+func TestWithExtensionsAndCustomNodeRenderer(t *testing.T) {
+	opts := []Option{
+		WithExtensions(&badgeExtension{}),
+		WithNodeRenderers(util.Prioritized(&badgeRenderer{}, 500)),
+	}
+
+	for _, format := range []Format{FormatBacklog, FormatMarkdown} {
+		result, err := Convert("状態: {{要対応}}", append(opts, WithFormat(format))...)
+		if err != nil {
+			t.Fatalf("予期しないエラーが発生しました: %v", err)
+		}
+
+		expected := "状態: &color(red){要対応}"
+		if result != expected {
+			t.Errorf("期待値: %q, 実際の値: %q", expected, result)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package converter
+
+// Settings はJSONで受け取る変換オプションです（HTTPサーバーのリクエストやLSPの初期化オプションで使います）
+// ローカルファイルの読み書きやコマンドの実行を伴うオプションは含みません
+type Settings struct {
+	// From は入力の形式です（markdown・html）
+	From string `json:"from,omitempty"`
+	// Format は出力形式です（backlog・markdown）
+	Format string `json:"format,omitempty"`
+	// TOC は目次マーカーの出力形式です（macro・list）
+	TOC string `json:"toc,omitempty"`
+	// Footnotes は脚注の出力形式です（notes・inline）
+	Footnotes string `json:"footnotes,omitempty"`
+	// AutoLink は自動リンクの出力形式です（bare・bracket）
+	AutoLink string `json:"autolink,omitempty"`
+	// BaseURL は相対リンクを解決する基準URLです
+	BaseURL string `json:"baseUrl,omitempty"`
+
+	HeadingOffset   int `json:"headingOffset,omitempty"`
+	MaxHeadingLevel int `json:"maxHeadingLevel,omitempty"`
+
+	TitleFromFirstH1 bool `json:"titleFromFirstH1,omitempty"`
+	DefinitionList   bool `json:"definitionList,omitempty"`
+	Typographer      bool `json:"typographer,omitempty"`
+	Linkify          bool `json:"linkify,omitempty"`
+	Emoji            bool `json:"emoji,omitempty"`
+	CodeTitle        bool `json:"codeTitle,omitempty"`
+}
+
+// Options は変換オプションをOptionに変換します
+func (s Settings) Options() ([]Option, error) {
+	var opts []Option
+
+	input, err := ParseInputFormat(s.From)
+	if err != nil {
+		return nil, err
+	}
+	format, err := ParseFormat(s.Format)
+	if err != nil {
+		return nil, err
+	}
+	toc, err := ParseTOCStyle(s.TOC)
+	if err != nil {
+		return nil, err
+	}
+	footnotes, err := ParseFootnoteStyle(s.Footnotes)
+	if err != nil {
+		return nil, err
+	}
+	autoLink, err := ParseAutoLinkStyle(s.AutoLink)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts,
+		WithInputFormat(input),
+		WithFormat(format),
+		WithTOC(toc),
+		WithFootnotes(footnotes),
+		WithAutoLinkStyle(autoLink),
+	)
+
+	if s.BaseURL != "" {
+		opts = append(opts, WithLinkResolver(&PathLinkResolver{BaseURL: s.BaseURL}))
+	}
+	if s.HeadingOffset != 0 {
+		opts = append(opts, WithHeadingOffset(s.HeadingOffset))
+	}
+	if s.MaxHeadingLevel != 0 {
+		opts = append(opts, WithMaxHeadingLevel(s.MaxHeadingLevel))
+	}
+	if s.TitleFromFirstH1 {
+		opts = append(opts, WithTitleFromFirstH1())
+	}
+	if s.DefinitionList {
+		opts = append(opts, WithDefinitionList())
+	}
+	if s.Typographer {
+		opts = append(opts, WithTypographer())
+	}
+	if s.Linkify {
+		opts = append(opts, WithLinkify())
+	}
+	if s.Emoji {
+		opts = append(opts, WithEmoji(nil))
+	}
+	if s.CodeTitle {
+		opts = append(opts, WithCodeTitle())
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// PreambleSlug は最初の見出しより前の部分の節のSlugです
+const PreambleSlug = "index"
+
+var (
+	// atxHeadingStart は # 形式の見出しの行の始まりです
+	atxHeadingStart = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t\r\n]|$)`)
+	// setextUnderline はSetext形式の見出しの下線です
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+)
+
+// Section はMarkdownを見出しで分割した節です
+type Section struct {
+	// Title は節の見出しのテキストです（最初の見出しより前の部分は空）
+	Title string
+	// Slug はTitleから作った識別子で、ファイル名などに使います（文書内で一意）
+	Slug string
+	// Heading は節の見出しのMarkdownです（最初の見出しより前の部分は空）
+	Heading string
+	// Body は見出しを除いた節のMarkdownです
+	// 節だけを変換しても参照リンクが解決されるよう、文書全体のリンク参照定義を末尾に加えます
+	Body string
+}
+
+// SplitSections はMarkdownを指定したレベルの見出しの位置で節に分割します
+// 分割するのは文書の最上位の見出しだけで、引用やリスト、コードブロック内の見出しでは分割しません
+// 最初の見出しより前に内容がある場合は、Slugが PreambleSlug の見出しのない節になります
+func SplitSections(markdown string, level int, opts ...Option) ([]Section, error) {
+	if level < 1 || level > 6 {
+		return nil, fmt.Errorf("invalid split level: %d", level)
+	}
+
+	source := []byte(markdown)
+	pc := parser.NewContext()
+	document := newMarkdown(newConfig(opts)).Parser().Parse(text.NewReader(source), parser.WithContext(pc))
+	definitions := referenceDefinitions(pc.References())
+
+	type boundary struct {
+		start, bodyStart int
+		title            string
+	}
+	var boundaries []boundary
+	for child := document.FirstChild(); child != nil; child = child.NextSibling() {
+		heading, ok := child.(*ast.Heading)
+		if !ok || heading.Level != level || heading.Lines().Len() == 0 {
+			continue
+		}
+		start := lineStart(source, heading.Lines().At(0).Start)
+		// 行の区間が改行を含む場合もあるため、区間の最後の文字を含む行の次から本文とする
+		last := heading.Lines().At(heading.Lines().Len() - 1)
+		bodyStart := lineEnd(source, max(last.Stop-1, last.Start))
+		// Setext形式の見出しは下線の行までを見出しとする
+		if !atxHeadingStart.Match(source[start:]) {
+			if underline := lineEnd(source, bodyStart); setextUnderline.Match(bytes.TrimRight(source[bodyStart:underline], "\r\n")) {
+				bodyStart = underline
+			}
+		}
+		boundaries = append(boundaries, boundary{start: start, bodyStart: bodyStart, title: plainText(heading, source)})
+	}
+
+	var sections []Section
+	slugs := map[string]bool{}
+	end := len(source)
+	if len(boundaries) > 0 {
+		end = boundaries[0].start
+	}
+	if preamble := markdown[:end]; strings.TrimSpace(preamble) != "" {
+		sections = append(sections, Section{Slug: uniqueSlug(PreambleSlug, slugs), Body: appendDefinitions(preamble, definitions)})
+	}
+	for i, b := range boundaries {
+		end := len(source)
+		if i+1 < len(boundaries) {
+			end = boundaries[i+1].start
+		}
+		sections = append(sections, Section{
+			Title:   b.title,
+			Slug:    uniqueSlug(Slugify(b.title), slugs),
+			Heading: markdown[b.start:b.bodyStart],
+			Body:    appendDefinitions(markdown[b.bodyStart:end], definitions),
+		})
+	}
+	return sections, nil
+}
+
+// referenceDefinitions はリンク参照定義をMarkdownの行に戻します（ラベル順）
+func referenceDefinitions(references []parser.Reference) string {
+	lines := make([]string, 0, len(references))
+	for _, reference := range references {
+		label := strings.ReplaceAll(string(reference.Label()), "\n", " ")
+		destination := markdownDestination(string(reference.Destination()))
+		if destination == "" {
+			destination = "<>"
+		}
+		lines = append(lines, "["+label+"]: "+destination+referenceTitle(reference.Title()))
+	}
+	slices.Sort(lines)
+	return strings.Join(lines, "\n")
+}
+
+// referenceTitle はリンク参照定義のタイトルを、タイトルに含まれない囲み文字で囲みます
+func referenceTitle(title []byte) string {
+	if len(title) == 0 {
+		return ""
+	}
+	for _, quotes := range []string{`""`, `''`, `()`} {
+		if !bytes.ContainsAny(title, quotes) {
+			return " " + quotes[:1] + string(title) + quotes[1:]
+		}
+	}
+	return ` "` + strings.ReplaceAll(string(title), `"`, `\"`) + `"`
+}
+
+// appendDefinitions は節の末尾にリンク参照定義を加えます（段落の続きにならないよう空行を挟みます）
+func appendDefinitions(body, definitions string) string {
+	if definitions == "" {
+		return body
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body + "\n" + definitions + "\n"
+}
+
+// Slugify は見出しのテキストから識別子を作ります
+// 文字と数字（日本語を含む）を小文字にして残し、それ以外の連続は - にまとめます
+func Slugify(title string) string {
+	var b strings.Builder
+	separator := false
+	for _, r := range strings.ToLower(title) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separator = true
+			continue
+		}
+		if separator && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		separator = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// uniqueSlug は文書内で重複しないよう、2つ目以降のSlugに -2, -3 を付けます
+func uniqueSlug(slug string, used map[string]bool) string {
+	if slug == "" {
+		slug = "section"
+	}
+	candidate := slug
+	for n := 2; used[candidate]; n++ {
+		candidate = slug + "-" + strconv.Itoa(n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// lineStart はoffsetを含む行の先頭の位置を返します
+func lineStart(source []byte, offset int) int {
+	return bytes.LastIndexByte(source[:offset], '\n') + 1
+}
+
+// lineEnd はoffsetを含む行の次の行の先頭の位置を返します（最後の行の場合は末尾）
+func lineEnd(source []byte, offset int) int {
+	if i := bytes.IndexByte(source[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(source)
+}
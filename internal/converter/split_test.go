@@ -0,0 +1,112 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		level    int
+		expected []Section
+	}{
+		{
+			name:  "H2で分割",
+			input: "# リリース計画\n\n概要です\n\n## 認証の改善\n\n内容1\n\n### 詳細\n\n内容2\n\n## Export API\n\n内容3\n",
+			level: 2,
+			expected: []Section{
+				{Slug: "index", Body: "# リリース計画\n\n概要です\n\n"},
+				{Title: "認証の改善", Slug: "認証の改善", Heading: "## 認証の改善\n", Body: "\n内容1\n\n### 詳細\n\n内容2\n\n"},
+				{Title: "Export API", Slug: "export-api", Heading: "## Export API\n", Body: "\n内容3\n"},
+			},
+		},
+		{
+			name:  "リンク参照定義を各節に加える",
+			input: "## A\n\nSee [spec][s].\n\n## B\n\n[s]: https://example.com/spec 'Spec \"v2\"'\n[空]: <a b>",
+			level: 2,
+			expected: []Section{
+				{Title: "A", Slug: "a", Heading: "## A\n", Body: "\nSee [spec][s].\n\n\n[s]: https://example.com/spec 'Spec \"v2\"'\n[空]: <a b>\n"},
+				{Title: "B", Slug: "b", Heading: "## B\n", Body: "\n[s]: https://example.com/spec 'Spec \"v2\"'\n[空]: <a b>\n\n[s]: https://example.com/spec 'Spec \"v2\"'\n[空]: <a b>\n"},
+			},
+		},
+		{
+			name:  "Setext形式の見出し",
+			input: "第1章\n=====\n本文1\n\n第2章\n=====\n本文2",
+			level: 1,
+			expected: []Section{
+				{Title: "第1章", Slug: "第1章", Heading: "第1章\n=====\n", Body: "本文1\n\n"},
+				{Title: "第2章", Slug: "第2章", Heading: "第2章\n=====\n", Body: "本文2"},
+			},
+		},
+		{
+			name:  "コードブロックと引用内の見出しでは分割しない",
+			input: "## A\n```\n## コード\n```\n> ## 引用\n",
+			level: 2,
+			expected: []Section{
+				{Title: "A", Slug: "a", Heading: "## A\n", Body: "```\n## コード\n```\n> ## 引用\n"},
+			},
+		},
+		{
+			name:  "重複するSlug",
+			input: "## Q&A\n\n## Q & A\n\n## !!\n",
+			level: 2,
+			expected: []Section{
+				{Title: "Q&A", Slug: "q-a", Heading: "## Q&A\n", Body: "\n"},
+				{Title: "Q & A", Slug: "q-a-2", Heading: "## Q & A\n", Body: "\n"},
+				{Title: "!!", Slug: "section", Heading: "## !!\n", Body: ""},
+			},
+		},
+		{
+			name:     "見出しがない",
+			input:    "本文だけ\n",
+			level:    2,
+			expected: []Section{{Slug: "index", Body: "本文だけ\n"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := SplitSections(tt.input, tt.level)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if !reflect.DeepEqual(sections, tt.expected) {
+				t.Errorf("期待値: %+v, 実際の値: %+v", tt.expected, sections)
+			}
+		})
+	}
+}
+
+func TestSplitSectionsInvalidLevel(t *testing.T) {
+	for _, level := range []int{0, 7} {
+		if _, err := SplitSections("## A\n", level); err == nil {
+			t.Errorf("レベル %d でエラーになりませんでした", level)
+		}
+	}
+}
+
+func TestExtractFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		frontMatter string
+		body        string
+	}{
+		{name: "フロントマター", input: "---\nwiki: Release\n---\n# 本文\n", frontMatter: "wiki: Release\n", body: "# 本文\n"},
+		{name: "...で終わる", input: "---\na: 1\n...\n本文", frontMatter: "a: 1\n", body: "本文"},
+		{name: "末尾で終わる", input: "---\na: 1\n---", frontMatter: "a: 1\n", body: ""},
+		{name: "フロントマターなし", input: "# 本文\n---\n", frontMatter: "", body: "# 本文\n---\n"},
+		{name: "閉じていない", input: "---\na: 1\n", frontMatter: "", body: "---\na: 1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontMatter, body := ExtractFrontMatter(tt.input)
+			if frontMatter != tt.frontMatter || body != tt.body {
+				t.Errorf("期待値: %q, %q, 実際の値: %q, %q", tt.frontMatter, tt.body, frontMatter, body)
+			}
+		})
+	}
+}
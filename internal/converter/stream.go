@@ -0,0 +1,314 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// streamChunkSize は入力を分割する目安の大きさです
+// ブロックの途中では分割しないため、1つのブロックがこれより大きい場合はブロック全体をまとめて変換します
+var streamChunkSize = 64 * 1024
+
+// ConvertTo はrから読み込んだMarkdownを変換し、wに逐次書き込みます
+// 入力をトップレベルのブロックの境界で分割して変換するため、巨大な入力でもメモリ使用量が入力の大きさに比例しません
+// 戻り値は変換時の警告です（行番号は入力全体での行番号です）
+//
+// 分割した部分ごとに変換するため、次の制限があります
+//   - 参照リンクの定義（[label]: url）は同じ部分の中でのみ解決されます
+//   - WithTOC・WithTitleFromFirstH1・WithFootnotes は文書全体が必要なため、指定された場合は入力をすべて読み込んでから変換します
+//     HTMLの入力（WithInputFormat(InputHTML)）も同様です
+//   - 添付ファイルの一覧は返しません。WithLocalAttachments を使う場合は ConvertDetailed を使ってください
+func ConvertTo(w io.Writer, r io.Reader, opts ...Option) ([]Warning, error) {
+	cfg := newConfig(opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.tocStyle != TOCNone || cfg.titleFromFirstH1 || cfg.footnotes != FootnoteNone || cfg.input == InputHTML {
+		input, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(input) == 0 {
+			return nil, nil
+		}
+		result, err := convertDocument(input, cfg)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.WriteString(w, result.Body)
+		return result.Warnings, err
+	}
+
+	s := &streamConverter{cfg: cfg, w: w}
+	splitter := newBlockSplitter(r)
+	for {
+		chunk, startLine, err := splitter.next()
+		if err != nil {
+			return s.warnings, err
+		}
+		if chunk == nil {
+			return s.warnings, nil
+		}
+		if err := s.convertChunk(chunk, startLine, splitter.more()); err != nil {
+			return s.warnings, err
+		}
+	}
+}
+
+// streamConverter は分割した入力を順に変換して書き込みます
+type streamConverter struct {
+	cfg      *config
+	w        io.Writer
+	written  bool // 本文を書き込み済みかどうか
+	warnings []Warning
+	// separator は直前の部分の後のブロック間の改行です（Backlog記法）
+	// 後に続く部分が空（空行やリンク参照定義だけ）の場合に末尾に残らないよう、次の本文を書き込むときまで保留します
+	separator string
+}
+
+// convertChunk は入力の一部を変換して書き込みます
+// startLineは部分の先頭の行番号、moreは後に続く部分があるかどうかです
+func (s *streamConverter) convertChunk(chunk []byte, startLine int, more bool) error {
+	if len(bytes.TrimSpace(chunk)) == 0 {
+		return nil
+	}
+
+	result, err := convert(chunk, s.cfg, more)
+	if err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		if warning.Line > 0 {
+			warning.Line += startLine - 1
+		}
+		s.warnings = append(s.warnings, warning)
+	}
+
+	body := result.Body
+	if s.cfg.format == FormatMarkdown {
+		// Markdownはブロック間を空行で区切る（各部分の末尾の改行は取り除かれている）
+		if body == "" {
+			return nil
+		}
+		if s.written {
+			body = "\n\n" + body
+		}
+	} else {
+		// Backlog記法では後続の部分がある場合のブロック間の改行を変換時に出力済み
+		content := strings.TrimRight(body, "\n")
+		if content == "" {
+			return nil
+		}
+		body = s.separator + content
+		s.separator = strings.Repeat("\n", len(result.Body)-len(content))
+	}
+
+	if _, err := io.WriteString(s.w, body); err != nil {
+		return err
+	}
+	s.written = s.written || body != ""
+	return nil
+}
+
+// blockSplitter は入力をトップレベルのブロックの境界で分割します
+// 空行の後に行頭から始まるリスト項目以外の行が続く位置を境界とし、フェンスコードブロックと
+// 空行を含められるHTMLブロック（コメントや<pre>など）の中では分割しません
+type blockSplitter struct {
+	reader *bufio.Reader
+	line   int // 読み込んだ行数
+	// pending は次の部分の先頭となる読み込み済みの行です
+	pending []byte
+	eof     bool
+}
+
+// newBlockSplitter は新しいblockSplitterを生成します
+func newBlockSplitter(r io.Reader) *blockSplitter {
+	return &blockSplitter{reader: bufio.NewReader(r)}
+}
+
+// more は後に続く部分があるかどうかを返します
+func (s *blockSplitter) more() bool {
+	return s.pending != nil
+}
+
+// next は次の部分とその先頭の行番号を返します。入力の終わりではnilを返します
+func (s *blockSplitter) next() ([]byte, int, error) {
+	if s.eof && s.pending == nil {
+		return nil, 0, nil
+	}
+
+	var chunk bytes.Buffer
+	var fence string   // 開いているフェンス（"```" など）
+	var htmlEnd string // 開いているHTMLブロックを閉じる文字列（"-->" など）
+	afterBlank := false
+	startLine := s.line + 1
+	if s.pending != nil {
+		startLine = s.line
+		chunk.Write(s.pending)
+		if fence = openingFence(s.pending); fence == "" {
+			htmlEnd = openingHTMLBlock(s.pending)
+		}
+		s.pending = nil
+	}
+	for !s.eof {
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, err
+		}
+		if errors.Is(err, io.EOF) {
+			s.eof = true
+			if len(line) == 0 {
+				break
+			}
+		}
+		s.line++
+
+		if fence == "" && htmlEnd == "" && afterBlank && chunk.Len() >= streamChunkSize && isBlockBoundary(line) {
+			s.pending = line
+			return chunk.Bytes(), startLine, nil
+		}
+		chunk.Write(line)
+
+		if fence != "" {
+			if isClosingFence(line, fence) {
+				fence = ""
+			}
+			afterBlank = false
+			continue
+		}
+		if htmlEnd != "" {
+			if bytes.Contains(bytes.ToLower(line), []byte(htmlEnd)) {
+				htmlEnd = ""
+			}
+			afterBlank = false
+			continue
+		}
+		if fence = openingFence(line); fence == "" {
+			htmlEnd = openingHTMLBlock(line)
+		}
+		afterBlank = len(bytes.TrimSpace(line)) == 0
+	}
+
+	// 入力の先頭から終わりまで空行だけでも、読み込んだ部分は返す（呼び出し側で空として扱う）
+	return chunk.Bytes(), startLine, nil
+}
+
+// isBlockBoundary は空行の後のこの行から新しいトップレベルのブロックを始めてよいかどうかを判定します
+// インデントされた行（リスト項目の続きやインデントコード）、リスト項目（リストの途中の可能性がある）と
+// 定義リストの定義（": " で始まり、空行の前の用語に続く）は除きます
+func isBlockBoundary(line []byte) bool {
+	if len(bytes.TrimSpace(line)) == 0 || line[0] == ' ' || line[0] == '\t' {
+		return false
+	}
+	return !isListItemLine(line) && !isDefinitionLine(line)
+}
+
+// isDefinitionLine は行が定義リストの定義の行かどうかを判定します
+func isDefinitionLine(line []byte) bool {
+	return line[0] == ':' && (len(line) == 1 || line[1] == ' ' || line[1] == '\t' || line[1] == '\n' || line[1] == '\r')
+}
+
+// htmlBlockStarts は空行を含められるHTMLブロック（CommonMarkの種類1〜5）の開始と、それを閉じる文字列です
+var htmlBlockStarts = []struct {
+	start string
+	end   string
+}{
+	{"<script", "</script>"},
+	{"<pre", "</pre>"},
+	{"<style", "</style>"},
+	{"<textarea", "</textarea>"},
+	{"<!--", "-->"},
+	{"<?", "?>"},
+	{"<![CDATA[", "]]>"},
+	{"<!", ">"},
+}
+
+// openingHTMLBlock は行が空行を含められるHTMLブロックの開始であれば、それを閉じる文字列を返します
+// 同じ行で閉じている場合は空文字列を返します
+func openingHTMLBlock(line []byte) string {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	lower := bytes.ToLower(trimmed)
+	for _, block := range htmlBlockStarts {
+		if !bytes.HasPrefix(lower, []byte(block.start)) {
+			continue
+		}
+		rest := lower[len(block.start):]
+		switch block.start {
+		case "<script", "<pre", "<style", "<textarea":
+			// タグ名の後は空白・">"・行末のいずれか
+			if len(rest) > 0 && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '>' && rest[0] != '\n' && rest[0] != '\r' {
+				continue
+			}
+		case "<!":
+			// 宣言（<!DOCTYPE など）は英字で始まる
+			if len(rest) == 0 || rest[0] < 'a' || rest[0] > 'z' {
+				continue
+			}
+		}
+		if bytes.Contains(rest, []byte(block.end)) {
+			return ""
+		}
+		return block.end
+	}
+	return ""
+}
+
+// isListItemLine は行がリスト項目のマーカーで始まるかどうかを判定します
+func isListItemLine(line []byte) bool {
+	switch line[0] {
+	case '-', '*', '+':
+		return len(line) == 1 || line[1] == ' ' || line[1] == '\t' || line[1] == '\n' || line[1] == '\r'
+	}
+
+	i := 0
+	for i < len(line) && i < 9 && line[i] >= '0' && line[i] <= '9' {
+		i++
+	}
+	if i == 0 || i >= len(line) || (line[i] != '.' && line[i] != ')') {
+		return false
+	}
+	return i+1 == len(line) || line[i+1] == ' ' || line[i+1] == '\t' || line[i+1] == '\n' || line[i+1] == '\r'
+}
+
+// openingFence は行がフェンスコードブロックの開始であればそのフェンスを返します
+func openingFence(line []byte) string {
+	trimmed := strings.TrimLeft(string(line), " ")
+	if len(string(line))-len(trimmed) > 3 {
+		return ""
+	}
+	for _, marker := range []byte{'`', '~'} {
+		n := 0
+		for n < len(trimmed) && trimmed[n] == marker {
+			n++
+		}
+		if n < 3 {
+			continue
+		}
+		// バッククォートのフェンスの情報文字列にはバッククォートを含められない
+		if marker == '`' && strings.ContainsRune(trimmed[n:], '`') {
+			return ""
+		}
+		return trimmed[:n]
+	}
+	return ""
+}
+
+// isClosingFence は行が開いているフェンスを閉じるかどうかを判定します
+func isClosingFence(line []byte, fence string) bool {
+	trimmed := strings.TrimLeft(string(line), " ")
+	if len(string(line))-len(trimmed) > 3 {
+		return false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == fence[0] {
+		n++
+	}
+	return n >= len(fence) && strings.TrimSpace(trimmed[n:]) == ""
+}
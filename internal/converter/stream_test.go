@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withStreamChunkSize はテストの間だけ分割の目安の大きさを変更します
+func withStreamChunkSize(t *testing.T, size int) {
+	t.Helper()
+	original := streamChunkSize
+	streamChunkSize = size
+	t.Cleanup(func() { streamChunkSize = original })
+}
+
+func TestConvertToMatchesConvert(t *testing.T) {
+	// すべての境界で分割しても一括変換と同じ結果になる
+	withStreamChunkSize(t, 1)
+
+	inputs := []struct {
+		name  string
+		input string
+		opts  []Option
+	}{
+		{name: "段落", input: "段落1\n\n段落2\n\n段落3"},
+		{name: "見出しと段落", input: "段落\n\n# 見出し\n\n本文\n\n## 小見出し"},
+		{name: "リストとネスト", input: "前\n\n- 項目1\n  - 項目2\n\n- 項目3\n\n1. 一\n\n2. 二\n\n後ろ"},
+		{name: "引用", input: "> 引用\n> > ネスト\n\n本文\n\n> 引用2"},
+		{name: "テーブル", input: "| a | b |\n|---|---|\n| 1 | 2 |\n\n本文"},
+		{name: "空行を含むコードブロック", input: "前\n\n```go\nfunc a() {}\n\nfunc b() {}\n```\n\n後ろ"},
+		{name: "HTMLと区切り線", input: "前\n\n<div>HTML</div>\n\n---\n\n後ろ"},
+		{name: "末尾の空行", input: "段落\n\n\n\n"},
+		{name: "空の入力", input: ""},
+		{name: "空行を含むHTMLコメント", input: "<!--\n\ncomment\n\n-->\n\npara"},
+		{name: "空行を挟む定義リスト", input: "term\n\n: def", opts: []Option{WithDefinitionList()}},
+		{name: "末尾のリンク参照定義", input: "段落\n\n[a]: https://example.com"},
+	}
+
+	formats := []Format{FormatBacklog, FormatMarkdown}
+	for _, tt := range inputs {
+		for _, format := range formats {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := append([]Option{WithFormat(format)}, tt.opts...)
+				expected, err := ConvertDetailed(tt.input, opts...)
+				if err != nil {
+					t.Fatalf("エラーが発生しました: %v", err)
+				}
+
+				var out strings.Builder
+				warnings, err := ConvertTo(&out, strings.NewReader(tt.input), opts...)
+				if err != nil {
+					t.Fatalf("エラーが発生しました: %v", err)
+				}
+				if out.String() != expected.Body {
+					t.Errorf("期待値: %q, 実際の値: %q", expected.Body, out.String())
+				}
+				if len(warnings) != len(expected.Warnings) || (len(warnings) > 0 && !reflect.DeepEqual(warnings, expected.Warnings)) {
+					t.Errorf("警告の期待値: %v, 実際の値: %v", expected.Warnings, warnings)
+				}
+			})
+		}
+	}
+}
+
+func TestConvertToWarningLines(t *testing.T) {
+	withStreamChunkSize(t, 1)
+
+	input := "段落\n\n<div>1</div>\n\n段落\n\n<div>2</div>"
+	warnings, err := ConvertTo(&strings.Builder{}, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("エラーが発生しました: %v", err)
+	}
+
+	var lines []int
+	for _, warning := range warnings {
+		lines = append(lines, warning.Line)
+	}
+	if expected := []int{3, 7}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("期待値: %v, 実際の値: %v", expected, lines)
+	}
+}
+
+func TestBlockSplitter(t *testing.T) {
+	withStreamChunkSize(t, 1)
+
+	input := "段落\n\n```\nコード\n\nコード\n```\n\n- 項目\n\n- 項目\n\n  続き\n\n<pre>\n\n</pre>\n\n段落"
+	splitter := newBlockSplitter(strings.NewReader(input))
+
+	var chunks []string
+	var startLines []int
+	for {
+		chunk, startLine, err := splitter.next()
+		if err != nil {
+			t.Fatalf("エラーが発生しました: %v", err)
+		}
+		if chunk == nil {
+			break
+		}
+		chunks = append(chunks, string(chunk))
+		startLines = append(startLines, startLine)
+	}
+
+	expected := []string{
+		"段落\n\n",
+		"```\nコード\n\nコード\n```\n\n- 項目\n\n- 項目\n\n  続き\n\n",
+		"<pre>\n\n</pre>\n\n",
+		"段落",
+	}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, chunks)
+	}
+	if expectedLines := []int{1, 3, 15, 19}; !reflect.DeepEqual(startLines, expectedLines) {
+		t.Errorf("開始行の期待値: %v, 実際の値: %v", expectedLines, startLines)
+	}
+}
+
+// errReader は読み込み時に必ずエラーを返すio.Readerです
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("読み込みエラー")
+}
+
+func TestConvertToReadError(t *testing.T) {
+	if _, err := ConvertTo(&strings.Builder{}, errReader{}); err == nil {
+		t.Error("エラーが発生しませんでした")
+	}
+}
@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// TOCStyle は目次マーカー（[TOC] または <!-- toc -->）の出力形式です
+type TOCStyle int
+
+const (
+	// TOCNone はマーカーを変換しません
+	TOCNone TOCStyle = iota
+	// TOCMacro はBacklogの #contents マクロを出力します
+	TOCMacro
+	// TOCList は見出しへのリンクの入れ子リストを出力します
+	TOCList
+)
+
+// tocPlaceholder は変換後に目次へ置き換えるための目印です
+const tocPlaceholder = "\x00toc\x00"
+
+// tocEntry は目次に載せる見出しを表します
+type tocEntry struct {
+	level int
+	text  string
+}
+
+// ParseTOCStyle は文字列から目次の出力形式を取得します
+func ParseTOCStyle(s string) (TOCStyle, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return TOCNone, nil
+	case "macro":
+		return TOCMacro, nil
+	case "list":
+		return TOCList, nil
+	}
+	return TOCNone, fmt.Errorf("unknown toc style: %q", s)
+}
+
+// isTOCParagraph はパラグラフが [TOC] マーカーかどうかを判定します
+func isTOCParagraph(paragraph *ast.Paragraph, source []byte) bool {
+	text := bytes.TrimSpace(paragraph.Lines().Value(source))
+	return strings.EqualFold(string(text), "[TOC]")
+}
+
+// isTOCHTMLBlock はHTMLブロックが <!-- toc --> マーカーかどうかを判定します
+func isTOCHTMLBlock(htmlBlock *ast.HTMLBlock, source []byte) bool {
+	text := strings.TrimSpace(string(htmlBlock.Lines().Value(source)))
+	if !strings.HasPrefix(text, "<!--") || !strings.HasSuffix(text, "-->") {
+		return false
+	}
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(text, "<!--"), "-->"))
+	return strings.EqualFold(text, "toc")
+}
+
+// renderTOC は目次をBacklog記法で生成します
+func renderTOC(style TOCStyle, entries []tocEntry) string {
+	if style == TOCMacro {
+		return "#contents"
+	}
+
+	// 空の見出しはリンクにできないため目次に載せない
+	entries = slices.DeleteFunc(slices.Clone(entries), func(entry tocEntry) bool {
+		return strings.TrimSpace(entry.text) == ""
+	})
+	if len(entries) == 0 {
+		return ""
+	}
+
+	// 最も浅い見出しレベルを1階層目とする
+	minLevel := entries[0].level
+	for _, entry := range entries {
+		if entry.level < minLevel {
+			minLevel = entry.level
+		}
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		prefix := strings.Repeat("-", entry.level-minLevel+1)
+		lines = append(lines, prefix+" "+tocLink(entry.text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tocLink は見出しへのリンクを返します
+// Backlogは見出しテキストをアンカー名として使用しますが、: や ] を含むとリンク名とURLの区切りが曖昧になるため、テキストのまま出力します
+func tocLink(text string) string {
+	if strings.ContainsAny(text, ":]") {
+		return text
+	}
+	return "[[" + text + ":#" + text + "]]"
+}
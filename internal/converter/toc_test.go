@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertWithTOC(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		style    TOCStyle
+		expected string
+	}{
+		{
+			name:     "オプションなしではマーカーをそのまま出力",
+			input:    "[TOC]\n\n# 概要",
+			style:    TOCNone,
+			expected: "[TOC]\n\n* 概要",
+		},
+		{
+			name:     "[TOC]を#contentsマクロに置換",
+			input:    "[TOC]\n\n# 概要\n## 詳細",
+			style:    TOCMacro,
+			expected: "#contents\n* 概要\n** 詳細",
+		},
+		{
+			name:     "HTMLコメントのマーカーを#contentsマクロに置換",
+			input:    "<!-- toc -->\n\n# 概要",
+			style:    TOCMacro,
+			expected: "#contents\n* 概要",
+		},
+		{
+			name:     "見出しリンクの入れ子リストを生成",
+			input:    "# タイトル\n\n[toc]\n\n## 概要\n### 背景\n## 詳細",
+			style:    TOCList,
+			expected: "* タイトル\n- [[タイトル:#タイトル]]\n-- [[概要:#概要]]\n--- [[背景:#背景]]\n-- [[詳細:#詳細]]\n** 概要\n*** 背景\n** 詳細",
+		},
+		{
+			name:     "空の見出しは目次に載せない",
+			input:    "[TOC]\n\n#\n## 概要",
+			style:    TOCList,
+			expected: "- [[概要:#概要]]\n* \n** 概要",
+		},
+		{
+			name:     "区切り文字を含む見出しはリンクにしない",
+			input:    "[TOC]\n\n# 手順: 準備\n# [a] b\n# 完了",
+			style:    TOCList,
+			expected: "- 手順: 準備\n- [a] b\n- [[完了:#完了]]\n* 手順: 準備\n* [a] b\n* 完了",
+		},
+		{
+			name:     "リスト項目の[TOC]はマーカーとみなさない",
+			input:    "- [TOC]\n- 手順\n\n# 概要",
+			style:    TOCMacro,
+			expected: "- [TOC]\n- 手順\n* 概要",
+		},
+		{
+			name:     "リスト項目の2つ目の段落の[TOC]はマーカーとみなさない",
+			input:    "- 手順\n\n  [TOC]\n\n# 概要",
+			style:    TOCMacro,
+			expected: "- 手順 [TOC]\n* 概要",
+		},
+		{
+			name:     "引用の中の[TOC]はマーカーとみなさない",
+			input:    "> [TOC]\n\n# 概要",
+			style:    TOCMacro,
+			expected: "> [TOC]\n\n* 概要",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.input, WithTOC(tt.style))
+			if err != nil {
+				t.Fatalf("予期しないエラーが発生しました: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseTOCStyle(t *testing.T) {
+	for input, expected := range map[string]TOCStyle{"": TOCNone, "none": TOCNone, "macro": TOCMacro, "LIST": TOCList} {
+		style, err := ParseTOCStyle(input)
+		if err != nil || style != expected {
+			t.Errorf("ParseTOCStyle(%q) = %v, %v; 期待値: %v", input, style, err, expected)
+		}
+	}
+
+	if _, err := ParseTOCStyle("unknown"); err == nil {
+		t.Errorf("期待されたエラーが発生しませんでした")
+	}
+}
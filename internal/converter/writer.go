@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Writer はNodeRendererFuncが変換結果を書き込む出力先です
+// 引用やリストの中では行頭の接頭辞（"> " やインデント）を自動的に付けます
+type Writer struct {
+	buffer      bytes.Buffer
+	prefixes    []*linePrefix
+	atLineStart bool
+	blank       bool // 直前の行が空行（または出力が空）かどうか
+	diag        *diagnostics
+}
+
+// linePrefix は行頭に付ける接頭辞（引用記号・リストのマーカーとインデント）です
+type linePrefix struct {
+	first string // 最初の行に付ける接頭辞
+	rest  string // 2行目以降に付ける接頭辞
+	used  bool
+}
+
+// newWriter は新しいWriterを生成します
+func newWriter(diag *diagnostics) *Writer {
+	return &Writer{atLineStart: true, blank: true, diag: diag}
+}
+
+// Write はバイト列を出力します
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteString(string(p))
+}
+
+// WriteString は文字列を出力します。改行のたびに接頭辞を付け直します
+func (w *Writer) WriteString(s string) (int, error) {
+	if len(w.prefixes) == 0 {
+		if s != "" {
+			// 接頭辞がなければ、改行だけの行を書いた時点で直前の行が空行になる
+			w.blank = strings.HasSuffix(s, "\n\n") || (s == "\n" && w.atLineStart)
+		}
+		w.atLineStart = strings.HasSuffix(s, "\n") || (s == "" && w.atLineStart)
+		return w.buffer.WriteString(s)
+	}
+
+	if s != "" {
+		w.blank = false
+	}
+
+	n := len(s)
+	for len(s) > 0 {
+		if w.atLineStart {
+			w.writePrefixes(false)
+			w.atLineStart = false
+		}
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			w.buffer.WriteString(s)
+			break
+		}
+		w.buffer.WriteString(s[:i+1])
+		w.atLineStart = true
+		s = s[i+1:]
+	}
+	return n, nil
+}
+
+// Warn は変換時の警告をノードの位置とともに記録します
+func (w *Writer) Warn(node ast.Node, format string, args ...any) {
+	w.diag.warn(node, format, args...)
+}
+
+// String はこれまでに出力した内容を返します
+func (w *Writer) String() string {
+	return w.buffer.String()
+}
+
+// endLine は行の途中であれば改行します
+func (w *Writer) endLine() {
+	if !w.atLineStart {
+		w.WriteString("\n")
+	}
+}
+
+// blankLine はブロック間の空行を出力します（空行が続く場合は出力しません）
+func (w *Writer) blankLine() {
+	if w.blank {
+		return
+	}
+	w.endLine()
+	w.writePrefixes(true)
+	w.buffer.WriteString("\n")
+	w.blank = true
+}
+
+// writePrefixes は行頭の接頭辞を出力します（空行では末尾の空白を除きます）
+func (w *Writer) writePrefixes(blank bool) {
+	var line strings.Builder
+	for _, prefix := range w.prefixes {
+		if prefix.used || blank {
+			line.WriteString(prefix.rest)
+		} else {
+			line.WriteString(prefix.first)
+			prefix.used = true
+		}
+	}
+	if blank {
+		w.buffer.WriteString(strings.TrimRight(line.String(), " "))
+		return
+	}
+	w.buffer.WriteString(line.String())
+}
+
+// pushPrefix は接頭辞を追加します
+func (w *Writer) pushPrefix(first, rest string) {
+	w.prefixes = append(w.prefixes, &linePrefix{first: first, rest: rest})
+}
+
+// popPrefix は最後に追加した接頭辞を取り除きます
+func (w *Writer) popPrefix() {
+	w.prefixes = w.prefixes[:len(w.prefixes)-1]
+}
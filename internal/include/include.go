@@ -0,0 +1,372 @@
+// Package include はMarkdownのinclude指示を展開し、共通の節から文書を組み立てます
+//
+// 次のどちらかの形式を単独の行に書くと、その行をファイルの内容に置き換えます
+//
+//	<!-- include: ./sections/setup.md lines=3-10 heading-offset=1 -->
+//	{{< include "./sections/setup.md" lines="3-10" heading-offset="auto" >}}
+//
+// パスは指示を書いたファイルからの相対パスです。指定できるパラメータは次のとおりです
+//   - lines: 埋め込む行の範囲（1始まり、"3-10"・"3-"・"-10"・"5"）
+//   - heading-offset: 埋め込む内容の見出し（# 形式）のレベルをずらす数
+//     "auto" の場合は、指示の直前の見出しの1つ下に最上位の見出しがくるようにずらします
+//
+// 埋め込む内容の相対リンクと画像は、展開後も同じファイルを指すよう最初の文書からのパスに書き換えます
+// コードブロック内の指示は展開しません
+package include
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// commentDirective は <!-- include: path --> 形式の指示です
+	commentDirective = regexp.MustCompile(`^ {0,3}<!--\s*include:\s*(.+?)\s*-->\s*$`)
+	// shortcodeDirective は {{< include path >}} 形式の指示です
+	shortcodeDirective = regexp.MustCompile(`^ {0,3}\{\{<\s*include\s+(.+?)\s*>\}\}\s*$`)
+	// atxHeading は # 形式の見出しです
+	atxHeading = regexp.MustCompile(`^( {0,3})(#{1,6})([ \t]|$)`)
+	// fenceLine はコードブロックの開始・終了の行です
+	fenceLine = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+	// inlineLinkDestination はインラインのリンクと画像のリンク先です
+	inlineLinkDestination = regexp.MustCompile(`(\]\()(<[^>\n]*>|[^)\s]+)`)
+	// externalDestination はURLやページ内リンクなど書き換えないリンク先です
+	externalDestination = regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.-]*:|#|/)`)
+)
+
+// Resolve はsourceのinclude指示を展開します
+// filename はsourceのファイル名で、相対パスの基準と循環の検出に使います（標準入力の場合は空）
+func Resolve(filename string, source []byte) ([]byte, error) {
+	doc := document{name: filename, dir: "."}
+	if filename == "" {
+		doc.name = "<stdin>"
+	} else {
+		doc.dir = filepath.Dir(filename)
+		doc.path = absPath(filename)
+	}
+
+	r := &resolver{rootDir: absPath(doc.dir)}
+	var stack []string
+	if doc.path != "" {
+		stack = []string{doc.path}
+	}
+	lines, err := r.expand(doc, splitLines(string(source)), stack)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// document は展開中のファイルです
+type document struct {
+	// name はエラーメッセージに使う名前です
+	name string
+	// dir は相対パスの基準のディレクトリです
+	dir string
+	// path は循環の検出に使う絶対パスです（標準入力の場合は空）
+	path string
+}
+
+// resolver はinclude指示を展開します
+type resolver struct {
+	// rootDir は最初の文書のディレクトリで、相対リンクの書き換えの基準です
+	rootDir string
+}
+
+// expand はlinesのinclude指示を展開した行を返します（各行は改行を含みます）
+// stack は展開中のファイルの一覧で、循環の検出に使います
+func (r *resolver) expand(doc document, lines []string, stack []string) ([]string, error) {
+	var result []string
+	var fence fenceState
+	// sectionLevel は直前の見出しのレベルで、heading-offset=autoに使います
+	sectionLevel := 0
+
+	for i, line := range lines {
+		if fence.update(line) || fence.open() {
+			result = append(result, line)
+			continue
+		}
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			sectionLevel = len(m[2])
+		}
+
+		args, ok := directiveArgs(line)
+		if !ok {
+			result = append(result, r.rewriteLinks(doc, line))
+			continue
+		}
+
+		included, err := r.include(doc, args, sectionLevel, stack)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", doc.name, i+1, err)
+		}
+		if len(included) > 0 && !strings.HasSuffix(included[len(included)-1], "\n") && strings.HasSuffix(line, "\n") {
+			included[len(included)-1] += "\n"
+		}
+		result = append(result, included...)
+	}
+	return result, nil
+}
+
+// include は1つのinclude指示を展開した行を返します
+func (r *resolver) include(parent document, args []string, sectionLevel int, stack []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("include: missing path")
+	}
+	target := args[0]
+	params := map[string]string{}
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("include %s: invalid parameter %q", target, arg)
+		}
+		params[key] = value
+	}
+
+	file := filepath.FromSlash(target)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(parent.dir, file)
+	}
+	doc := document{name: file, dir: filepath.Dir(file), path: absPath(file)}
+	data, stack, err := ReadFile(file, stack)
+	if err != nil {
+		if errors.Is(err, errCycle) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("include %s: %w", target, err)
+	}
+	lines := splitLines(string(data))
+
+	for key, value := range params {
+		switch key {
+		case "lines":
+			if lines, err = selectLines(lines, value); err != nil {
+				return nil, fmt.Errorf("include %s: %w", target, err)
+			}
+		case "heading-offset":
+		default:
+			return nil, fmt.Errorf("include %s: unknown parameter %q", target, key)
+		}
+	}
+
+	expanded, err := r.expand(doc, lines, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	switch value := params["heading-offset"]; value {
+	case "":
+	case "auto":
+		if top := topHeadingLevel(expanded); top > 0 {
+			offset = sectionLevel + 1 - top
+		}
+	default:
+		if offset, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("include %s: invalid heading-offset %q", target, value)
+		}
+	}
+	return shiftHeadings(expanded, offset), nil
+}
+
+// errCycle はファイルが自身を直接または間接に埋め込んでいることを表します
+var errCycle = errors.New("include cycle")
+
+// ReadFile はファイルを読み込み、その内容と、展開中のファイルの一覧stackにファイルを加えた一覧を返します
+// ファイルがすでにstackにある場合は循環としてエラーを返します
+// テンプレートのincludeなど、ファイルを入れ子に埋め込むほかの処理でも循環の検出に使います
+func ReadFile(file string, stack []string) ([]byte, []string, error) {
+	included := append(slices.Clone(stack), absPath(file))
+	if slices.Contains(stack, absPath(file)) {
+		return nil, nil, fmt.Errorf("%w: %s", errCycle, strings.Join(included, " -> "))
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, included, nil
+}
+
+// rewriteLinks は埋め込んだファイルの相対リンクを最初の文書からのパスに書き換えます
+func (r *resolver) rewriteLinks(doc document, line string) string {
+	dir := absPath(doc.dir)
+	if dir == r.rootDir {
+		return line
+	}
+	return inlineLinkDestination.ReplaceAllStringFunc(line, func(match string) string {
+		m := inlineLinkDestination.FindStringSubmatch(match)
+		destination, bracketed := m[2], strings.HasPrefix(m[2], "<")
+		if bracketed {
+			destination = destination[1 : len(destination)-1]
+		}
+		if destination == "" || externalDestination.MatchString(destination) {
+			return match
+		}
+		rel, err := filepath.Rel(r.rootDir, filepath.Join(dir, filepath.FromSlash(destination)))
+		if err != nil {
+			return match
+		}
+		rewritten := path.Clean(filepath.ToSlash(rel))
+		if bracketed {
+			rewritten = "<" + rewritten + ">"
+		}
+		return m[1] + rewritten
+	})
+}
+
+// directiveArgs はinclude指示の行から引数を取り出します（引数の先頭はパスです）
+func directiveArgs(line string) ([]string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	m := commentDirective.FindStringSubmatch(line)
+	if m == nil {
+		m = shortcodeDirective.FindStringSubmatch(line)
+	}
+	if m == nil {
+		return nil, false
+	}
+	return splitArgs(m[1]), true
+}
+
+// splitArgs は空白で区切られた引数を分けます（引用符で囲んだ部分は区切らず、引用符は取り除きます）
+func splitArgs(s string) []string {
+	var args []string
+	var b strings.Builder
+	var quote rune
+	inArg := false
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				b.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args
+}
+
+// selectLines は "3-10" 形式の範囲の行を返します
+func selectLines(lines []string, spec string) ([]string, error) {
+	startSpec, endSpec, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		endSpec = startSpec
+	}
+
+	start, end := 1, len(lines)
+	var err error
+	if startSpec != "" {
+		if start, err = strconv.Atoi(startSpec); err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid lines %q", spec)
+		}
+	}
+	if endSpec != "" {
+		if end, err = strconv.Atoi(endSpec); err != nil || end < start {
+			return nil, fmt.Errorf("invalid lines %q", spec)
+		}
+	}
+	if start > len(lines) {
+		return nil, fmt.Errorf("lines %q: file has %d lines", spec, len(lines))
+	}
+	return lines[start-1 : min(end, len(lines))], nil
+}
+
+// topHeadingLevel はコードブロックの外の最上位の見出しのレベルを返します（見出しがない場合は0）
+func topHeadingLevel(lines []string) int {
+	top := 0
+	var fence fenceState
+	for _, line := range lines {
+		if fence.update(line) || fence.open() {
+			continue
+		}
+		if m := atxHeading.FindStringSubmatch(line); m != nil && (top == 0 || len(m[2]) < top) {
+			top = len(m[2])
+		}
+	}
+	return top
+}
+
+// shiftHeadings はコードブロックの外の見出しのレベルをoffsetだけずらします（1から6の範囲に収めます）
+func shiftHeadings(lines []string, offset int) []string {
+	if offset == 0 {
+		return lines
+	}
+	var fence fenceState
+	for i, line := range lines {
+		if fence.update(line) || fence.open() {
+			continue
+		}
+		if m := atxHeading.FindStringSubmatchIndex(line); m != nil {
+			level := min(max(m[5]-m[4]+offset, 1), 6)
+			lines[i] = line[:m[4]] + strings.Repeat("#", level) + line[m[5]:]
+		}
+	}
+	return lines
+}
+
+// fenceState はコードブロックの中かどうかを追跡します
+type fenceState struct {
+	marker string
+}
+
+func (f *fenceState) open() bool { return f.marker != "" }
+
+// update は行がコードブロックの開始・終了であれば状態を更新してtrueを返します
+func (f *fenceState) update(line string) bool {
+	m := fenceLine.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		return false
+	}
+	if f.marker == "" {
+		// ` のフェンスの情報文字列には ` を含められない
+		if m[1][0] == '`' && strings.Contains(m[2], "`") {
+			return false
+		}
+		f.marker = m[1]
+		return true
+	}
+	if m[1][0] == f.marker[0] && len(m[1]) >= len(f.marker) && strings.TrimSpace(m[2]) == "" {
+		f.marker = ""
+		return true
+	}
+	return false
+}
+
+// splitLines は改行を含めて行に分けます
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// absPath は循環の検出のためにパスを絶対パスにします
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
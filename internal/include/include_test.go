@@ -0,0 +1,118 @@
+package include
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles はテスト用のファイルを作成し、そのディレクトリを返す
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("ディレクトリを作成できません: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("ファイルを作成できません: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"sections/setup.md":       "# セットアップ\n\n手順です\n\n## 前提\n\n![図](img/setup.png)\n",
+		"sections/lines.md":       "1行目\n2行目\n3行目\n4行目\n",
+		"sections/nested.md":      "## 入れ子\n<!-- include: common/note.md -->\n",
+		"sections/common/note.md": "[注意](../../docs/note.md) [外部](https://example.com) [節](#a)\n",
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "コメント形式",
+			input:    "# 手順書\n\n<!-- include: sections/setup.md -->\n\n以上\n",
+			expected: "# 手順書\n\n# セットアップ\n\n手順です\n\n## 前提\n\n![図](sections/img/setup.png)\n\n以上\n",
+		},
+		{
+			name:     "ショートコード形式と見出しのずらし",
+			input:    `{{< include "./sections/setup.md" heading-offset="1" lines="1-5" >}}` + "\n",
+			expected: "## セットアップ\n\n手順です\n\n### 前提\n",
+		},
+		{
+			name:     "直前の見出しに合わせる",
+			input:    "# 手順書\n## 準備\n<!-- include: sections/setup.md lines=1 heading-offset=auto -->\n",
+			expected: "# 手順書\n## 準備\n### セットアップ\n",
+		},
+		{
+			name:     "行の範囲",
+			input:    "<!-- include: sections/lines.md lines=2-3 -->\n<!-- include: sections/lines.md lines=4- -->\n<!-- include: sections/lines.md lines=-1 -->",
+			expected: "2行目\n3行目\n4行目\n1行目\n",
+		},
+		{
+			name:     "入れ子の展開と相対リンク",
+			input:    "<!-- include: sections/nested.md -->\n",
+			expected: "## 入れ子\n[注意](docs/note.md) [外部](https://example.com) [節](#a)\n",
+		},
+		{
+			name:     "コードブロック内は展開しない",
+			input:    "```markdown\n<!-- include: sections/setup.md -->\n```\n",
+			expected: "```markdown\n<!-- include: sections/setup.md -->\n```\n",
+		},
+		{
+			name:     "文中の指示は展開しない",
+			input:    "説明 <!-- include: sections/setup.md --> です\n",
+			expected: "説明 <!-- include: sections/setup.md --> です\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Resolve(filepath.Join(dir, "index.md"), []byte(tt.input))
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestResolveError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.md": "<!-- include: a.md -->\n",
+		"a.md":     "a\n<!-- include: b.md -->\n",
+		"b.md":     "<!-- include: index.md -->\n",
+		"short.md": "1行目\n",
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "循環", input: "<!-- include: a.md -->\n", expected: "index.md:1: " + filepath.Join(dir, "a.md") + ":2: " + filepath.Join(dir, "b.md") + ":1: include cycle: "},
+		{name: "ファイルがない", input: "x\n<!-- include: missing.md -->\n", expected: "index.md:2: include missing.md: open "},
+		{name: "範囲外の行", input: "<!-- include: short.md lines=3-4 -->\n", expected: `include short.md: lines "3-4": file has 1 lines`},
+		{name: "不正な範囲", input: "<!-- include: short.md lines=2-1 -->\n", expected: `invalid lines "2-1"`},
+		{name: "未知のパラメータ", input: "<!-- include: short.md level=2 -->\n", expected: `unknown parameter "level"`},
+		{name: "不正な見出しのずらし", input: "<!-- include: short.md heading-offset=x -->\n", expected: `invalid heading-offset "x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Resolve(filepath.Join(dir, "index.md"), []byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("期待値: %q, 実際の値: %v", tt.expected, err)
+			}
+		})
+	}
+}
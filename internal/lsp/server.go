@@ -0,0 +1,387 @@
+// Package lsp はMarkdownの変換結果をエディタに提供するLanguage Server Protocolのサーバーを実装します
+//
+// 標準入出力でJSON-RPCのメッセージをやり取りし、次の機能を提供します
+//   - 変換時の警告（非対応の記法・情報が失われる変換）をDiagnosticsとして通知します
+//   - 「Copy as Backlog」のコードアクションを提供します（コマンドの結果として変換結果を返すため、クリップボードへのコピーはエディタ側で行います）
+//   - 独自のリクエスト md2backlog/preview で変換結果を返します
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"md2backlog/internal/converter"
+)
+
+const (
+	// CommandCopyAsBacklog は文書をBacklog記法に変換した結果を返すコマンドです
+	CommandCopyAsBacklog = "md2backlog.copyAsBacklog"
+	// MethodPreview は変換結果を返す独自のリクエストです
+	MethodPreview = "md2backlog/preview"
+
+	// diagnosticSource はDiagnosticsの発生元として表示する名前です
+	diagnosticSource = "md2backlog"
+)
+
+// ErrExitWithoutShutdown はshutdownを受け取る前にexitを受け取ったことを表します（終了コード1で終了すべき状態です）
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Server はLSPのサーバーです
+type Server struct {
+	// Version はinitializeの応答で返すバージョンです
+	Version string
+	// Options は変換オプションです（initializeのinitializationOptionsで上書きできます）
+	Options converter.Settings
+
+	out          io.Writer
+	documents    map[string]string
+	initialized  bool
+	shuttingDown bool
+}
+
+// NewServer は新しいServerを生成します
+func NewServer(version string, options converter.Settings) *Server {
+	return &Server{Version: version, Options: options, documents: map[string]string{}}
+}
+
+// Run はrからメッセージを読み取り、wに応答と通知を書き込みます
+// exitを受け取るか入力が終わると戻ります
+func (s *Server) Run(r io.Reader, w io.Writer) error {
+	s.out = w
+	reader := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			if err := s.reply(nil, nil, rpcErr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shuttingDown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.dispatch(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch はメッセージをメソッドごとの処理に振り分け、リクエストであれば応答を書き込みます
+func (s *Server) dispatch(msg *message) error {
+	if msg.Method == "" {
+		// クライアントからのレスポンスは使わない
+		return nil
+	}
+
+	result, rpcErr := s.handle(msg)
+	if msg.isNotification() {
+		if rpcErr != nil {
+			// 通知には応答できないため、エラーはログとしてクライアントに送る
+			return s.logMessage(msg.Method + ": " + rpcErr.Message)
+		}
+		return nil
+	}
+	return s.reply(msg.ID, result, rpcErr)
+}
+
+// handle はメソッドを処理し、結果を返します
+func (s *Server) handle(msg *message) (any, *responseError) {
+	if !s.initialized && msg.Method != "initialize" {
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return s.initialize(msg.Params)
+	case "initialized", "$/setTrace", "$/cancelRequest":
+		return nil, nil
+	case "shutdown":
+		s.shuttingDown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   textDocumentIdentifier `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		// 全文同期のため最後の変更が文書全体です
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		if err := s.publishDiagnostics(params.TextDocument.URI, []diagnostic{}); err != nil {
+			return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		return nil, nil
+	case "textDocument/codeAction":
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return []codeAction{{
+			Title: "Copy as Backlog",
+			Kind:  "source",
+			Command: &command{
+				Title:     "Copy as Backlog",
+				Command:   CommandCopyAsBacklog,
+				Arguments: []any{params.TextDocument.URI},
+			},
+		}}, nil
+	case "workspace/executeCommand":
+		var params struct {
+			Command   string   `json:"command"`
+			Arguments []string `json:"arguments"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if params.Command != CommandCopyAsBacklog || len(params.Arguments) != 1 {
+			return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown command: %s", params.Command)}
+		}
+		result, rpcErr := s.convert(params.Arguments[0])
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return result.Body, nil
+	case MethodPreview:
+		var params struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		result, rpcErr := s.convert(params.TextDocument.URI)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		preview := previewResult{Body: result.Body, Title: result.Title, Warnings: result.Warnings}
+		if preview.Warnings == nil {
+			preview.Warnings = []converter.Warning{}
+		}
+		return preview, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// initialize はクライアントの設定を受け取り、サーバーの機能を返します
+func (s *Server) initialize(params json.RawMessage) (any, *responseError) {
+	var p struct {
+		InitializationOptions *converter.Settings `json:"initializationOptions"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(err)
+	}
+	if p.InitializationOptions != nil {
+		s.Options = *p.InitializationOptions
+	}
+	s.initialized = true
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			// 1: 文書全体を同期
+			"textDocumentSync":   1,
+			"codeActionProvider": true,
+			"executeCommandProvider": map[string]any{
+				"commands": []string{CommandCopyAsBacklog},
+			},
+		},
+		"serverInfo": map[string]string{"name": "md2backlog", "version": s.Version},
+	}, nil
+}
+
+// update は文書の内容を更新し、Diagnosticsを通知します
+func (s *Server) update(uri, text string) *responseError {
+	s.documents[uri] = text
+	result, rpcErr := s.convert(uri)
+	if rpcErr != nil {
+		return rpcErr
+	}
+
+	diagnostics := make([]diagnostic, 0, len(result.Warnings))
+	for _, warning := range result.Warnings {
+		diagnostics = append(diagnostics, newDiagnostic(text, warning))
+	}
+	if err := s.publishDiagnostics(uri, diagnostics); err != nil {
+		return &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	return nil
+}
+
+// convert は開いている文書を変換します
+func (s *Server) convert(uri string) (*converter.Result, *responseError) {
+	text, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: "document is not open: " + uri}
+	}
+	opts, err := s.Options.Options()
+	if err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	// フロントマターは変換せず、警告の行番号を文書全体での行番号にずらす
+	frontMatter, markdown := converter.ExtractFrontMatter(text)
+	result, err := converter.ConvertDetailed(markdown, opts...)
+	if err != nil {
+		return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	if frontMatter != "" {
+		// --- の2行とフロントマターの行
+		offset := strings.Count(frontMatter, "\n") + 2
+		for i := range result.Warnings {
+			if result.Warnings[i].Line != 0 {
+				result.Warnings[i].Line += offset
+			}
+		}
+	}
+	return result, nil
+}
+
+// publishDiagnostics はDiagnosticsをクライアントに通知します
+func (s *Server) publishDiagnostics(uri string, diagnostics []diagnostic) error {
+	params, err := json.Marshal(map[string]any{"uri": uri, "diagnostics": diagnostics})
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// logMessage はエラーのログをクライアントに通知します
+func (s *Server) logMessage(text string) error {
+	params, err := json.Marshal(map[string]any{"type": messageTypeError, "message": text})
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: "window/logMessage", Params: params})
+}
+
+// reply はリクエストへの応答を書き込みます
+func (s *Server) reply(id *json.RawMessage, result any, rpcErr *responseError) error {
+	msg := &message{ID: id, Error: rpcErr}
+	if id == nil {
+		// 解析できなかったリクエストへの応答のIDはnull
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+	if rpcErr == nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = body
+	}
+	return writeMessage(s.out, msg)
+}
+
+// invalidParams はパラメータの解析エラーを返します
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+// textDocumentIdentifier は文書を識別します
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// position は文書内の位置です（文字位置はUTF-16のコード単位で数えます）
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// textRange は文書内の範囲です
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+// diagnostic は文書に表示する警告です
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+// command はクライアントが実行するコマンドです
+type command struct {
+	Title     string `json:"title"`
+	Command   string `json:"command"`
+	Arguments []any  `json:"arguments,omitempty"`
+}
+
+// codeAction はコードアクションです
+type codeAction struct {
+	Title   string   `json:"title"`
+	Kind    string   `json:"kind"`
+	Command *command `json:"command"`
+}
+
+// previewResult は md2backlog/preview の結果です
+type previewResult struct {
+	Body     string              `json:"body"`
+	Title    string              `json:"title,omitempty"`
+	Warnings []converter.Warning `json:"warnings"`
+}
+
+const (
+	// severityWarning はDiagnosticの重要度「警告」です
+	severityWarning = 2
+	// messageTypeError はwindow/logMessageの種類「エラー」です
+	messageTypeError = 1
+)
+
+// newDiagnostic は変換時の警告を行全体を範囲とするDiagnosticにします（行番号がない場合は先頭行）
+func newDiagnostic(text string, warning converter.Warning) diagnostic {
+	line := max(warning.Line-1, 0)
+	lines := strings.Split(text, "\n")
+	var length int
+	if line < len(lines) {
+		length = len(utf16.Encode([]rune(strings.TrimSuffix(lines[line], "\r"))))
+	}
+	return diagnostic{
+		Range:    textRange{Start: position{Line: line}, End: position{Line: line, Character: length}},
+		Severity: severityWarning,
+		Source:   diagnosticSource,
+		Message:  warning.Message,
+	}
+}
@@ -0,0 +1,343 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"md2backlog/internal/converter"
+)
+
+// request はテスト用のリクエスト・通知を組み立てます（idが0の場合は通知）
+func request(id int, method string, params any) string {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method}
+	if id != 0 {
+		msg["id"] = id
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	body, _ := json.Marshal(msg)
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// runSession はメッセージを順に送り、サーバーが書き込んだメッセージを返します
+func runSession(t *testing.T, messages ...string) ([]message, error) {
+	t.Helper()
+	var in bytes.Buffer
+	for _, m := range messages {
+		in.WriteString(m)
+	}
+
+	var out bytes.Buffer
+	err := NewServer("test", converter.Settings{}).Run(&in, &out)
+
+	var written []message
+	reader := bufio.NewReader(&out)
+	for {
+		msg, readErr := readMessage(reader)
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			t.Fatalf("出力の読み取りに失敗しました: %v", readErr)
+		}
+		written = append(written, *msg)
+	}
+	return written, err
+}
+
+// response はIDが一致する応答を返します
+func response(t *testing.T, messages []message, id int) message {
+	t.Helper()
+	for _, msg := range messages {
+		if msg.ID != nil && string(*msg.ID) == fmt.Sprint(id) {
+			return msg
+		}
+	}
+	t.Fatalf("ID %d の応答がありません", id)
+	return message{}
+}
+
+// notifications は指定したメソッドの通知を返します
+func notifications(messages []message, method string) []message {
+	var result []message
+	for _, msg := range messages {
+		if msg.Method == method {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+const testURI = "file:///tmp/test.md"
+
+func didOpen(text string) string {
+	return request(0, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": testURI, "languageId": "markdown", "version": 1, "text": text},
+	})
+}
+
+func TestLifecycle(t *testing.T) {
+	messages, err := runSession(t,
+		request(1, "initialize", map[string]any{"capabilities": map[string]any{}}),
+		request(0, "initialized", map[string]any{}),
+		request(2, "shutdown", nil),
+		request(0, "exit", nil),
+	)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	var result struct {
+		Capabilities struct {
+			TextDocumentSync       int  `json:"textDocumentSync"`
+			CodeActionProvider     bool `json:"codeActionProvider"`
+			ExecuteCommandProvider struct {
+				Commands []string `json:"commands"`
+			} `json:"executeCommandProvider"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(response(t, messages, 1).Result, &result); err != nil {
+		t.Fatalf("initializeの応答を解析できません: %v", err)
+	}
+	if result.Capabilities.TextDocumentSync != 1 || !result.Capabilities.CodeActionProvider {
+		t.Errorf("予期しないcapabilities: %+v", result.Capabilities)
+	}
+	if commands := result.Capabilities.ExecuteCommandProvider.Commands; !reflect.DeepEqual(commands, []string{CommandCopyAsBacklog}) {
+		t.Errorf("期待値: %q, 実際の値: %q", []string{CommandCopyAsBacklog}, commands)
+	}
+	if result.ServerInfo.Version != "test" {
+		t.Errorf("期待値: %q, 実際の値: %q", "test", result.ServerInfo.Version)
+	}
+
+	if shutdown := response(t, messages, 2); string(shutdown.Result) != "null" || shutdown.Error != nil {
+		t.Errorf("shutdownの応答が不正です: %+v", shutdown)
+	}
+}
+
+func TestLifecycleErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		code     int
+		exitErr  error
+	}{
+		{
+			name:     "initialize前のリクエスト",
+			messages: []string{request(1, MethodPreview, map[string]any{})},
+			code:     codeServerNotInitialized,
+		},
+		{
+			name:     "未知のメソッド",
+			messages: []string{request(2, "initialize", map[string]any{}), request(1, "textDocument/hover", map[string]any{})},
+			code:     codeMethodNotFound,
+		},
+		{
+			name:     "開いていない文書",
+			messages: []string{request(2, "initialize", map[string]any{}), request(1, MethodPreview, map[string]any{"textDocument": map[string]any{"uri": testURI}})},
+			code:     codeInvalidParams,
+		},
+		{
+			name:     "shutdown前のexit",
+			messages: []string{request(2, "initialize", map[string]any{}), request(0, "exit", nil), request(1, "shutdown", nil)},
+			exitErr:  ErrExitWithoutShutdown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := runSession(t, tt.messages...)
+			if !errors.Is(err, tt.exitErr) {
+				t.Fatalf("期待値: %v, 実際の値: %v", tt.exitErr, err)
+			}
+			if tt.code == 0 {
+				return
+			}
+			if got := response(t, messages, 1); got.Error == nil || got.Error.Code != tt.code {
+				t.Errorf("期待値: %d, 実際の値: %+v", tt.code, got.Error)
+			}
+		})
+	}
+}
+
+func TestNotificationError(t *testing.T) {
+	messages, err := runSession(t,
+		request(1, "initialize", map[string]any{"initializationOptions": map[string]any{"format": "html"}}),
+		didOpen("a"),
+	)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	logs := notifications(messages, "window/logMessage")
+	if len(logs) != 1 {
+		t.Fatalf("期待値: 1件, 実際の値: %d件", len(logs))
+	}
+	var params struct {
+		Type    int    `json:"type"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(logs[0].Params, &params); err != nil {
+		t.Fatalf("通知を解析できません: %v", err)
+	}
+	expected := `textDocument/didOpen: unknown format: "html"`
+	if params.Type != messageTypeError || params.Message != expected {
+		t.Errorf("期待値: %q, 実際の値: %+v", expected, params)
+	}
+}
+
+func TestPublishDiagnostics(t *testing.T) {
+	messages, err := runSession(t,
+		request(1, "initialize", map[string]any{}),
+		didOpen("# 見出し\n\n<div>あ𠮷</div>\n"),
+		request(0, "textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": testURI, "version": 2},
+			"contentChanges": []map[string]any{{"text": "# 見出し\n"}},
+		}),
+		request(0, "textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": testURI}}),
+	)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	published := notifications(messages, "textDocument/publishDiagnostics")
+	if len(published) != 3 {
+		t.Fatalf("期待値: 3件, 実際の値: %d件", len(published))
+	}
+
+	var params struct {
+		URI         string       `json:"uri"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+	expected := [][]diagnostic{
+		{{
+			// 「𠮷」はUTF-16で2単位
+			Range:    textRange{Start: position{Line: 2}, End: position{Line: 2, Character: 14}},
+			Severity: severityWarning,
+			Source:   diagnosticSource,
+			Message:  "raw HTML is not supported and was removed",
+		}},
+		{},
+		{},
+	}
+	for i, msg := range published {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatalf("通知を解析できません: %v", err)
+		}
+		if params.URI != testURI {
+			t.Errorf("期待値: %q, 実際の値: %q", testURI, params.URI)
+		}
+		if !reflect.DeepEqual(params.Diagnostics, expected[i]) {
+			t.Errorf("%d件目 期待値: %+v, 実際の値: %+v", i+1, expected[i], params.Diagnostics)
+		}
+	}
+}
+
+func TestPublishDiagnosticsFrontMatter(t *testing.T) {
+	messages, err := runSession(t,
+		request(1, "initialize", map[string]any{}),
+		didOpen("---\ntitle: 見出し\n---\n\n<div>html</div>\n"),
+	)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	published := notifications(messages, "textDocument/publishDiagnostics")
+	if len(published) != 1 {
+		t.Fatalf("期待値: 1件, 実際の値: %d件", len(published))
+	}
+	var params struct {
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(published[0].Params, &params); err != nil {
+		t.Fatalf("通知を解析できません: %v", err)
+	}
+	// フロントマターは警告にならず、HTMLブロックの警告は文書全体での行を指す
+	expected := []diagnostic{{
+		Range:    textRange{Start: position{Line: 4}, End: position{Line: 4, Character: 15}},
+		Severity: severityWarning,
+		Source:   diagnosticSource,
+		Message:  "raw HTML is not supported and was removed",
+	}}
+	if !reflect.DeepEqual(params.Diagnostics, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, params.Diagnostics)
+	}
+}
+
+func TestCopyAsBacklog(t *testing.T) {
+	messages, err := runSession(t,
+		request(1, "initialize", map[string]any{"initializationOptions": map[string]any{"titleFromFirstH1": true}}),
+		didOpen("# 件名\n\n**本文**\n"),
+		request(2, "textDocument/codeAction", map[string]any{
+			"textDocument": map[string]any{"uri": testURI},
+			"range":        textRange{},
+			"context":      map[string]any{"diagnostics": []any{}},
+		}),
+		request(3, "workspace/executeCommand", map[string]any{"command": CommandCopyAsBacklog, "arguments": []string{testURI}}),
+		request(4, MethodPreview, map[string]any{"textDocument": map[string]any{"uri": testURI}}),
+	)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	var actions []codeAction
+	if err := json.Unmarshal(response(t, messages, 2).Result, &actions); err != nil {
+		t.Fatalf("codeActionの応答を解析できません: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Command.Command != CommandCopyAsBacklog || actions[0].Command.Arguments[0] != testURI {
+		t.Errorf("予期しないコードアクション: %+v", actions)
+	}
+
+	var body string
+	if err := json.Unmarshal(response(t, messages, 3).Result, &body); err != nil {
+		t.Fatalf("executeCommandの応答を解析できません: %v", err)
+	}
+	if body != "''本文''" {
+		t.Errorf("期待値: %q, 実際の値: %q", "''本文''", body)
+	}
+
+	var preview previewResult
+	if err := json.Unmarshal(response(t, messages, 4).Result, &preview); err != nil {
+		t.Fatalf("previewの応答を解析できません: %v", err)
+	}
+	expected := previewResult{Body: "''本文''", Title: "件名", Warnings: []converter.Warning{}}
+	if !reflect.DeepEqual(preview, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, preview)
+	}
+}
+
+func TestPreviewWarnings(t *testing.T) {
+	messages, err := runSession(t,
+		request(1, "initialize", map[string]any{}),
+		didOpen("> 引用\n> # 見出し\n"),
+		request(2, MethodPreview, map[string]any{"textDocument": map[string]any{"uri": testURI}}),
+	)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	var preview struct {
+		Body     string           `json:"body"`
+		Warnings []map[string]any `json:"warnings"`
+	}
+	if err := json.Unmarshal(response(t, messages, 2).Result, &preview); err != nil {
+		t.Fatalf("previewの応答を解析できません: %v", err)
+	}
+	expected := []map[string]any{{"line": float64(2), "message": "heading in a blockquote is not supported and was removed"}}
+	if preview.Body != "> 引用" || !reflect.DeepEqual(preview.Warnings, expected) {
+		t.Errorf("期待値: %q %v, 実際の値: %q %v", "> 引用", expected, preview.Body, preview.Warnings)
+	}
+	if published := notifications(messages, "textDocument/publishDiagnostics"); len(published) != 1 {
+		t.Errorf("期待値: 1件, 実際の値: %d件", len(published))
+	}
+}
@@ -0,0 +1,106 @@
+// Package render はGoのtext/templateで書かれたMarkdownのテンプレートを展開します
+//
+// 課題のテンプレートのように、同じ形の文書を値を差し替えて作るために使います
+// テンプレートでは次の関数を使えます
+//
+//	date "2006-01-02"   現在の日時をGoのレイアウトで書式化します
+//	issue "PROJ-123"    課題キーを課題へのリンクにします（BacklogURLを指定しない場合は課題キーのまま）
+//	include "a.md"      別のファイルをテンプレートとして展開して埋め込みます（相対パスは埋め込むファイルからの相対）
+//	env "NAME"          環境変数の値を返します
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"md2backlog/internal/include"
+)
+
+// issueKeyPattern は課題キーの形式です
+var issueKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// Renderer はテンプレートを展開します
+type Renderer struct {
+	// Values はテンプレートに渡す値です（テンプレートでは {{ .name }} で参照します）
+	Values map[string]any
+	// BacklogURL は issue で課題へのリンクを作るためのスペースのURLです
+	BacklogURL string
+	// Now は date で使う現在の日時を返します（nilの場合はtime.Now）
+	Now func() time.Time
+}
+
+// RenderFile はファイルのテンプレートを展開します
+func (r *Renderer) RenderFile(path string) (string, error) {
+	data, stack, err := include.ReadFile(path, nil)
+	if err != nil {
+		return "", err
+	}
+	return r.render(filepath.Base(path), filepath.Dir(path), string(data), stack)
+}
+
+// Render はテンプレートを展開します
+// include の相対パスはdirからの相対パスとして解決します
+func (r *Renderer) Render(text, dir string) (string, error) {
+	return r.render("template", dir, text, nil)
+}
+
+// render はテンプレートを展開します（nameはエラーメッセージに使う名前、dirはincludeの基準のディレクトリ）
+// stack は展開中のファイルの一覧で、includeの循環を検出するために使います
+func (r *Renderer) render(name, dir, text string, stack []string) (string, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(r.funcs(dir, stack)).
+		Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, r.Values); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// funcs はテンプレートで使える関数を返します
+func (r *Renderer) funcs(dir string, stack []string) template.FuncMap {
+	return template.FuncMap{
+		"date": func(layout string) string {
+			now := time.Now
+			if r.Now != nil {
+				now = r.Now
+			}
+			return now().Format(layout)
+		},
+		"issue": func(key string) (string, error) {
+			if !issueKeyPattern.MatchString(key) {
+				return "", fmt.Errorf("invalid issue key: %q", key)
+			}
+			if r.BacklogURL == "" {
+				return key, nil
+			}
+			return fmt.Sprintf("[%s](%s/view/%s)", key, strings.TrimSuffix(r.BacklogURL, "/"), key), nil
+		},
+		// 埋め込むファイルの末尾の改行は、{{ include }} を単独の行に書いたときに空行が増えないよう取り除く
+		"include": func(path string) (string, error) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, included, err := include.ReadFile(path, stack)
+			if err != nil {
+				return "", err
+			}
+			text, err := r.render(filepath.Base(path), filepath.Dir(path), string(data), included)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(text, "\n"), nil
+		},
+		"env": os.Getenv,
+	}
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	t.Setenv("MD2BACKLOG_TEST_ENV", "staging")
+	renderer := &Renderer{
+		Values: map[string]any{
+			"version": "1.2",
+			"hosts":   []any{"web1", "web2"},
+			"service": map[string]any{"name": "api"},
+		},
+		BacklogURL: "https://example.backlog.com/",
+		Now:        func() time.Time { return time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC) },
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "値の参照", template: "# v{{ .version }} の障害", expected: "# v1.2 の障害"},
+		{name: "入れ子の値", template: "{{ .service.name }}", expected: "api"},
+		{name: "繰り返し", template: "{{ range .hosts }}- {{ . }}\n{{ end }}", expected: "- web1\n- web2\n"},
+		{name: "日付", template: `{{ date "2006-01-02 15:04" }}`, expected: "2024-05-01 09:30"},
+		{name: "課題キー", template: `{{ issue "PROJ-12" }}`, expected: "[PROJ-12](https://example.backlog.com/view/PROJ-12)"},
+		{name: "環境変数", template: `{{ env "MD2BACKLOG_TEST_ENV" }}`, expected: "staging"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderer.Render(tt.template, ".")
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	renderer := &Renderer{Values: map[string]any{}}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "未定義の値", template: "{{ .missing }}", expected: `map has no entry for key "missing"`},
+		{name: "不正な課題キー", template: `{{ issue "proj-1" }}`, expected: `invalid issue key: "proj-1"`},
+		{name: "構文エラー", template: "{{ .a ", expected: "unclosed action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderer.Render(tt.template, ".")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("期待値: %q, 実際の値: %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRenderFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bug.md":                    "# {{ .title }}\n\n{{ include \"sections/env.md\" }}\n",
+		"sections/env.md":           "環境: {{ .env }}\n{{ include \"common/footer.md\" }}\n",
+		"sections/common/footer.md": "以上\n",
+		"cycle.md":                  "{{ include \"cycle2.md\" }}",
+		"cycle2.md":                 "{{ include \"cycle.md\" }}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("ディレクトリを作成できません: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("ファイルを作成できません: %v", err)
+		}
+	}
+
+	renderer := &Renderer{Values: map[string]any{"title": "障害", "env": "prod"}}
+	result, err := renderer.RenderFile(filepath.Join(dir, "bug.md"))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expected := "# 障害\n\n環境: prod\n以上\n"
+	if result != expected {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, result)
+	}
+
+	if _, err := renderer.RenderFile(filepath.Join(dir, "cycle.md")); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("循環のエラーが期待されましたが、実際の値: %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseValues はテンプレートに渡す値をYAMLから読み取ります
+//
+// 値のファイルに使う範囲のYAMLに対応します
+//   - インデントによる入れ子のマッピングとシーケンス（"- "）
+//   - 引用符で囲んだ文字列・真偽値・整数・null、[a, b] 形式のシーケンス（小数は文字列のまま）
+//   - | と > の複数行の文字列
+//   - # で始まるコメント
+//
+// アンカー・タグ・複数のドキュメントなどには対応していません
+func ParseValues(data []byte) (map[string]any, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		p.lines = append(p.lines, newYAMLLine(i+1, raw))
+	}
+
+	p.skipBlank()
+	if p.done() {
+		return map[string]any{}, nil
+	}
+	line := p.current()
+	if isSequenceItem(line.text) {
+		return nil, fmt.Errorf("line %d: top level must be a mapping", line.number)
+	}
+	value, err := p.parseMapping(line.indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipBlank(); !p.done() {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.current().number)
+	}
+	return value, nil
+}
+
+// yamlLine はYAMLの1行です
+type yamlLine struct {
+	number int
+	indent int
+	// raw はコメントを取り除く前の行で、複数行の文字列に使います
+	raw string
+	// text はインデントとコメントを取り除いた内容です（空の場合は空行）
+	text string
+}
+
+func newYAMLLine(number int, raw string) yamlLine {
+	trimmed := strings.TrimLeft(raw, " ")
+	text := strings.TrimSpace(stripComment(trimmed))
+	if text == "---" {
+		text = ""
+	}
+	return yamlLine{number: number, indent: len(raw) - len(trimmed), raw: raw, text: text}
+}
+
+// yamlParser は行単位でYAMLを読み取ります
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) done() bool         { return p.pos >= len(p.lines) }
+func (p *yamlParser) current() *yamlLine { return &p.lines[p.pos] }
+
+// skipBlank は空行とコメントだけの行を読み飛ばします
+func (p *yamlParser) skipBlank() {
+	for !p.done() && p.current().text == "" {
+		p.pos++
+	}
+}
+
+// parseBlock はindentより深い位置から始まる入れ子の値を読み取ります
+// キーの値がない場合（key:）に使い、入れ子がなければnullです
+func (p *yamlParser) parseBlock(parent int, allowSequence bool) (any, error) {
+	p.skipBlank()
+	if p.done() {
+		return nil, nil
+	}
+	line := p.current()
+	// キーと同じインデントのシーケンス（key:\n- a）も許可する
+	if allowSequence && line.indent == parent && isSequenceItem(line.text) {
+		return p.parseSequence(line.indent)
+	}
+	if line.indent <= parent {
+		return nil, nil
+	}
+	if isSequenceItem(line.text) {
+		return p.parseSequence(line.indent)
+	}
+	return p.parseMapping(line.indent)
+}
+
+// parseMapping はindentの位置のマッピングを読み取ります
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	result := map[string]any{}
+	for p.skipBlank(); !p.done(); p.skipBlank() {
+		line := p.current()
+		if line.indent < indent || (line.indent == indent && isSequenceItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+
+		key, rest, ok := splitMappingEntry(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		p.pos++
+
+		value, err := p.parseValue(rest, indent, line.number, true)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseSequence はindentの位置のシーケンスを読み取ります
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	result := []any{}
+	for p.skipBlank(); !p.done(); p.skipBlank() {
+		line := p.current()
+		if line.indent != indent || !isSequenceItem(line.text) {
+			if line.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+			}
+			break
+		}
+
+		rest := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		if _, _, ok := splitMappingEntry(rest); ok && !isQuoted(rest) {
+			// "- key: value" は要素のマッピングの最初の行として読み直す
+			itemIndent := indent + len(line.text) - len(strings.TrimLeft(line.text[1:], " "))
+			*line = yamlLine{number: line.number, indent: itemIndent, raw: line.raw, text: rest}
+			value, err := p.parseMapping(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+			continue
+		}
+
+		p.pos++
+		value, err := p.parseValue(rest, indent, line.number, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// parseValue はキーまたはシーケンスの要素の値を読み取ります
+func (p *yamlParser) parseValue(text string, indent, number int, allowSequence bool) (any, error) {
+	switch {
+	case text == "":
+		return p.parseBlock(indent, allowSequence)
+	case strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">"):
+		return p.parseBlockScalar(text, indent), nil
+	}
+	return parseScalar(text, number)
+}
+
+// parseBlockScalar は | と > の複数行の文字列を読み取ります
+// 末尾の改行は |- のように - を付けた場合は取り除き、それ以外は1つにまとめます
+func (p *yamlParser) parseBlockScalar(header string, parent int) string {
+	var lines []string
+	contentIndent := -1
+	for ; !p.done(); p.pos++ {
+		line := p.current()
+		if strings.TrimSpace(line.raw) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if line.indent <= parent {
+			break
+		}
+		if contentIndent < 0 {
+			contentIndent = line.indent
+		}
+		lines = append(lines, line.raw[min(contentIndent, line.indent):])
+	}
+	// 後続の値との間の空行は文字列に含めない
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var text string
+	if strings.HasPrefix(header, ">") {
+		text = foldLines(lines)
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+	if strings.Contains(header, "-") || text == "" {
+		return text
+	}
+	return text + "\n"
+}
+
+// foldLines は > の文字列の行を空白でつなぎます（空行は改行になります）
+func foldLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		switch {
+		case line == "":
+			b.WriteString("\n")
+		case i > 0 && lines[i-1] != "":
+			b.WriteString(" ")
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// parseScalar は1行の値を読み取ります
+func parseScalar(text string, number int) (any, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", number, text)
+		}
+		return s, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", number, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("line %d: unterminated sequence %s", number, text)
+		}
+		items := []any{}
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		if inner == "" {
+			return items, nil
+		}
+		for _, item := range splitFlowItems(inner) {
+			value, err := parseScalar(strings.TrimSpace(item), number)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	}
+
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	// 小数や0で始まる数字はバージョン番号（1.10）や番号（0123）のことが多いため文字列のままにする
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil && (text == "0" || !strings.HasPrefix(strings.TrimLeft(text, "+-"), "0")) {
+		return int(n), nil
+	}
+	return text, nil
+}
+
+// splitFlowItems は [a, b] 形式のシーケンスの中身を要素に分けます
+// 引用符で囲んだ要素の中の "," では分けません（['a, b', "c"]）
+func splitFlowItems(inner string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(inner); i++ {
+		switch c := inner[i]; {
+		case quote == '"' && c == '\\':
+			// ダブルクォートの中のエスケープ（\"）を読み飛ばす
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && strings.TrimSpace(inner[start:i]) == "":
+			quote = c
+		case c == ',':
+			items = append(items, inner[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, inner[start:])
+}
+
+// splitMappingEntry は "key: value" をキーと値に分けます
+func splitMappingEntry(text string) (key, value string, ok bool) {
+	var i int
+	if isQuoted(text) {
+		// 引用符で囲んだキー
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		i = end + 2
+		if !strings.HasPrefix(text[i:], ":") {
+			return "", "", false
+		}
+		key = text[1 : end+1]
+	} else {
+		i = strings.Index(text, ": ")
+		if i < 0 {
+			if !strings.HasSuffix(text, ":") {
+				return "", "", false
+			}
+			i = len(text) - 1
+		}
+		key = strings.TrimSpace(text[:i])
+	}
+	if key == "" {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(text[i+1:]), true
+}
+
+// stripComment は引用符の外の " #" 以降のコメントを取り除きます
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [,", text[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isQuoted(text string) bool {
+	return strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'")
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]any
+	}{
+		{
+			name:     "空のファイル",
+			input:    "# コメントだけ\n\n",
+			expected: map[string]any{},
+		},
+		{
+			name:  "スカラー",
+			input: "name: 障害報告\nversion: 1.10\ncount: 3\nzip: 0123\nenabled: true\nempty: null\nquoted: \"a # b\\n\"\nsingle: 'it''s'\ntext: don't # コメント\nurl: https://example.com/#top\n",
+			expected: map[string]any{
+				"name": "障害報告", "version": "1.10", "count": 3, "zip": "0123", "enabled": true, "empty": nil,
+				"quoted": "a # b\n", "single": "it's", "text": "don't", "url": "https://example.com/#top",
+			},
+		},
+		{
+			name:  "入れ子のマッピング",
+			input: "service:\n  name: api\n  owner:\n    team: infra\nenv: prod\n",
+			expected: map[string]any{
+				"service": map[string]any{"name": "api", "owner": map[string]any{"team": "infra"}},
+				"env":     "prod",
+			},
+		},
+		{
+			name:  "シーケンス",
+			input: "hosts:\n  - web1\n  - web2\nsteps:\n- a\n- b\ntags: [db, 2]\nnone:\n",
+			expected: map[string]any{
+				"hosts": []any{"web1", "web2"},
+				"steps": []any{"a", "b"},
+				"tags":  []any{"db", 2},
+				"none":  nil,
+			},
+		},
+		{
+			name:     "引用符で囲んだ要素のシーケンス",
+			input:    "list: [a, 'b, c', \"d\", \"e \\\" f, g\"]",
+			expected: map[string]any{"list": []any{"a", "b, c", "d", "e \" f, g"}},
+		},
+		{
+			name:  "マッピングのシーケンス",
+			input: "members:\n  - name: 山田\n    role: lead\n  - name: 佐藤\n",
+			expected: map[string]any{
+				"members": []any{
+					map[string]any{"name": "山田", "role": "lead"},
+					map[string]any{"name": "佐藤"},
+				},
+			},
+		},
+		{
+			name:  "複数行の文字列",
+			input: "body: |\n  # 手順\n\n  1. 再起動\nfolded: >-\n  一行目\n  続き\n\n  次の段落\nnext: x\n",
+			expected: map[string]any{
+				"body":   "# 手順\n\n1. 再起動\n",
+				"folded": "一行目 続き\n次の段落",
+				"next":   "x",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseValues([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("期待値: %#v, 実際の値: %#v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseValuesError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "トップレベルがシーケンス", input: "- a\n", expected: "line 1: top level must be a mapping"},
+		{name: "キーがない", input: "a: 1\njust text\n", expected: "line 2: expected \"key: value\""},
+		{name: "キーの重複", input: "a: 1\na: 2\n", expected: "line 2: duplicate key \"a\""},
+		{name: "不正なインデント", input: "a: 1\n  b: 2\n", expected: "line 2: unexpected indentation"},
+		{name: "閉じていない引用符", input: "a: \"x\n", expected: "line 1: invalid quoted string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseValues([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("期待値: %q, 実際の値: %v", tt.expected, err)
+			}
+		})
+	}
+}
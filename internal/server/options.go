@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"md2backlog/internal/converter"
+)
+
+// Options はリクエストで指定できる変換オプションです
+// JSONのリクエストではoptionsフィールド、テキストのリクエストではクエリパラメータで指定します
+type Options = converter.Settings
+
+// parseQueryOptions はクエリパラメータから変換オプションを読み取ります
+func parseQueryOptions(query url.Values) (Options, error) {
+	opts := Options{
+		From:      query.Get("from"),
+		Format:    query.Get("format"),
+		TOC:       query.Get("toc"),
+		Footnotes: query.Get("footnotes"),
+		AutoLink:  query.Get("autolink"),
+		BaseURL:   query.Get("baseUrl"),
+	}
+
+	ints := map[string]*int{
+		"headingOffset":   &opts.HeadingOffset,
+		"maxHeadingLevel": &opts.MaxHeadingLevel,
+	}
+	for name, value := range ints {
+		if s := query.Get(name); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return Options{}, fmt.Errorf("invalid %s: %q", name, s)
+			}
+			*value = n
+		}
+	}
+
+	bools := map[string]*bool{
+		"titleFromFirstH1": &opts.TitleFromFirstH1,
+		"definitionList":   &opts.DefinitionList,
+		"typographer":      &opts.Typographer,
+		"linkify":          &opts.Linkify,
+		"emoji":            &opts.Emoji,
+		"codeTitle":        &opts.CodeTitle,
+	}
+	for name, value := range bools {
+		if s := query.Get(name); s != "" {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return Options{}, fmt.Errorf("invalid %s: %q", name, s)
+			}
+			*value = b
+		}
+	}
+
+	return opts, nil
+}
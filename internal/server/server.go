@@ -0,0 +1,288 @@
+// Package server はMarkdownからBacklog記法への変換をHTTPで提供します
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"md2backlog/internal/converter"
+)
+
+const (
+	// DefaultMaxBodyBytes はリクエストボディの大きさの既定の上限です
+	DefaultMaxBodyBytes = 1 << 20
+	// DefaultMaxBatchSize は一括変換できる文書の数の既定の上限です
+	DefaultMaxBatchSize = 100
+)
+
+// Config はサーバーの設定です
+type Config struct {
+	// Version は /version で返すバージョンです
+	Version string
+	// MaxBodyBytes はリクエストボディの大きさの上限です（0の場合はDefaultMaxBodyBytes）
+	MaxBodyBytes int64
+	// MaxBatchSize は一括変換できる文書の数の上限です（0の場合はDefaultMaxBatchSize）
+	MaxBatchSize int
+	// Logger はリクエストのログの出力先です（nilの場合はslog.Default()）
+	Logger *slog.Logger
+}
+
+// Server は変換APIのhttp.Handlerです
+//
+//	POST /convert        1つの文書を変換します
+//	POST /convert/batch  複数の文書をまとめて変換します
+//	GET  /healthz        稼働状態を返します
+//	GET  /version        バージョンを返します
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+}
+
+// ConvertRequest は /convert のJSONリクエストです
+type ConvertRequest struct {
+	// Markdown は変換する文書です（Options.Fromがhtmlの場合はHTML）
+	Markdown string  `json:"markdown"`
+	Options  Options `json:"options"`
+}
+
+// ConvertResponse は /convert のJSONレスポンスです
+type ConvertResponse struct {
+	Body     string    `json:"body"`
+	Title    string    `json:"title,omitempty"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// BatchRequest は /convert/batch のリクエストです
+type BatchRequest struct {
+	Documents []BatchDocument `json:"documents"`
+	// Options はすべての文書に共通の変換オプションです
+	Options Options `json:"options"`
+}
+
+// BatchDocument は一括変換する文書です
+type BatchDocument struct {
+	// ID はレスポンスで文書を識別するための値です（任意）
+	ID       string `json:"id,omitempty"`
+	Markdown string `json:"markdown"`
+	// Options を指定した場合は共通の変換オプションの代わりに使います
+	Options *Options `json:"options,omitempty"`
+}
+
+// BatchResponse は /convert/batch のレスポンスです
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult は一括変換した文書ごとの結果です（変換に失敗した場合はErrorを設定します）
+type BatchResult struct {
+	ID string `json:"id,omitempty"`
+	ConvertResponse
+	Error string `json:"error,omitempty"`
+}
+
+// Warning は変換時の警告です
+type Warning struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// errorResponse はエラーのレスポンスです
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New は新しいServerを生成します
+func New(cfg Config) *Server {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /convert", s.handleConvert)
+	s.mux.HandleFunc("POST /convert/batch", s.handleBatch)
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /version", s.handleVersion)
+	return s
+}
+
+// ServeHTTP はリクエストを処理し、その結果をログに出力します
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	r.Body = http.MaxBytesReader(recorder, r.Body, s.cfg.MaxBodyBytes)
+
+	s.mux.ServeHTTP(recorder, r)
+
+	s.cfg.Logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", recorder.status),
+		slog.Int64("bytes", recorder.bytes),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("remote", r.RemoteAddr),
+	)
+}
+
+// handleConvert は1つの文書を変換します
+// JSONのリクエストにはJSONで、それ以外（Markdownをそのまま送信）にはテキストで変換結果を返します
+func (s *Server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	var req ConvertRequest
+	jsonRequest := isJSON(r.Header.Get("Content-Type"))
+	if jsonRequest {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeBodyError(w, err)
+			return
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.writeBodyError(w, err)
+			return
+		}
+		req.Markdown = string(body)
+		if req.Options, err = parseQueryOptions(r.URL.Query()); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	result, err := convert(req.Markdown, req.Options)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	if jsonRequest || isJSON(r.Header.Get("Accept")) {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	for _, warning := range result.Warnings {
+		w.Header().Add("X-Conversion-Warning", converter.Warning{Line: warning.Line, Message: warning.Message}.String())
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, result.Body)
+}
+
+// handleBatch は複数の文書をまとめて変換します
+// 文書ごとの変換の失敗はレスポンス全体の失敗とせず、その文書の結果のErrorに設定します
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBodyError(w, err)
+		return
+	}
+	if len(req.Documents) > s.cfg.MaxBatchSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{
+			Error: fmt.Sprintf("too many documents: %d (max %d)", len(req.Documents), s.cfg.MaxBatchSize),
+		})
+		return
+	}
+
+	response := BatchResponse{Results: make([]BatchResult, len(req.Documents))}
+	for i, document := range req.Documents {
+		options := req.Options
+		if document.Options != nil {
+			options = *document.Options
+		}
+
+		response.Results[i].ID = document.ID
+		result, err := convert(document.Markdown, options)
+		if err != nil {
+			response.Results[i].Error = err.Error()
+			continue
+		}
+		response.Results[i].ConvertResponse = *result
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleHealth は稼働状態を返します
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleVersion はバージョンを返します
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"version": s.cfg.Version})
+}
+
+// writeBodyError はリクエストボディの読み取りエラーを返します（上限を超えた場合は413）
+func (s *Server) writeBodyError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{
+			Error: fmt.Sprintf("request body too large (max %d bytes)", maxBytesErr.Limit),
+		})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+}
+
+// convert は文書を変換し、レスポンスの形式にします
+func convert(markdown string, options Options) (*ConvertResponse, error) {
+	opts, err := options.Options()
+	if err != nil {
+		return nil, err
+	}
+	result, err := converter.ConvertDetailed(markdown, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ConvertResponse{Body: result.Body, Title: result.Title, Warnings: []Warning{}}
+	for _, warning := range result.Warnings {
+		response.Warnings = append(response.Warnings, Warning{Line: warning.Line, Message: warning.Message})
+	}
+	return response, nil
+}
+
+// writeJSON は値をJSONで書き込みます
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// isJSON はメディアタイプがJSONかどうかを判定します
+func isJSON(contentType string) bool {
+	for _, part := range strings.Split(contentType, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusRecorder はログに出力するためにステータスコードと書き込んだバイト数を記録します
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader はステータスコードを記録します
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write は書き込んだバイト数を記録します
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestServer はログを破棄するテスト用のサーバーを生成します
+func newTestServer(cfg Config) *httptest.Server {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return httptest.NewServer(New(cfg))
+}
+
+func TestConvertText(t *testing.T) {
+	server := newTestServer(Config{})
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		expected string
+	}{
+		{name: "既定のオプション", body: "# 見出し\n\n**太字**", expected: "* 見出し\n''太字''"},
+		{name: "クエリのオプション", query: "?headingOffset=1&format=backlog", body: "# 見出し", expected: "** 見出し"},
+		{name: "Markdown出力", query: "?format=markdown", body: "* 項目", expected: "- 項目"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/convert"+tt.query, "text/markdown", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("リクエストに失敗しました: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("予期しないステータス: %d %s", resp.StatusCode, body)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("期待値: %q, 実際の値: %q", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestConvertTextWarnings(t *testing.T) {
+	server := newTestServer(Config{})
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/convert", "text/plain", strings.NewReader("a\n\n<div>x</div>"))
+	if err != nil {
+		t.Fatalf("リクエストに失敗しました: %v", err)
+	}
+	defer resp.Body.Close()
+
+	expected := []string{"line 3: raw HTML is not supported and was removed"}
+	if warnings := resp.Header.Values("X-Conversion-Warning"); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("期待値: %q, 実際の値: %q", expected, warnings)
+	}
+}
+
+func TestConvertJSON(t *testing.T) {
+	server := newTestServer(Config{})
+	defer server.Close()
+
+	request := `{"markdown": "# 件名\n\n本文\n\n<div>x</div>", "options": {"titleFromFirstH1": true}}`
+	resp, err := http.Post(server.URL+"/convert", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("リクエストに失敗しました: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result ConvertResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗しました: %v", err)
+	}
+	expected := ConvertResponse{
+		Body:     "本文",
+		Title:    "件名",
+		Warnings: []Warning{{Line: 5, Message: "raw HTML is not supported and was removed"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, result)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	server := newTestServer(Config{MaxBodyBytes: 16})
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{name: "不正なオプション", path: "/convert?toc=wrong", contentType: "text/plain", body: "a", status: http.StatusBadRequest},
+		{name: "不正な数値", path: "/convert?headingOffset=x", contentType: "text/plain", body: "a", status: http.StatusBadRequest},
+		{name: "不正なJSON", path: "/convert", contentType: "application/json", body: "{", status: http.StatusBadRequest},
+		{name: "大きすぎるボディ", path: "/convert", contentType: "text/plain", body: strings.Repeat("a", 17), status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+tt.path, tt.contentType, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("リクエストに失敗しました: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("期待値: %d, 実際の値: %d", tt.status, resp.StatusCode)
+			}
+			var body errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+				t.Errorf("エラーメッセージが返されていません: %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertBatch(t *testing.T) {
+	server := newTestServer(Config{MaxBatchSize: 2})
+	defer server.Close()
+
+	request := BatchRequest{
+		Documents: []BatchDocument{
+			{ID: "a", Markdown: "# A"},
+			{ID: "b", Markdown: "# B", Options: &Options{Format: "unknown"}},
+		},
+		Options: Options{HeadingOffset: 1},
+	}
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(request)
+
+	resp, err := http.Post(server.URL+"/convert/batch", "application/json", &body)
+	if err != nil {
+		t.Fatalf("リクエストに失敗しました: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗しました: %v", err)
+	}
+	expected := BatchResponse{Results: []BatchResult{
+		{ID: "a", ConvertResponse: ConvertResponse{Body: "** A", Warnings: []Warning{}}},
+		{ID: "b", Error: `unknown format: "unknown"`},
+	}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("期待値: %+v, 実際の値: %+v", expected, result)
+	}
+}
+
+func TestConvertBatchTooMany(t *testing.T) {
+	server := newTestServer(Config{MaxBatchSize: 1})
+	defer server.Close()
+
+	request := `{"documents": [{"markdown": "a"}, {"markdown": "b"}]}`
+	resp, err := http.Post(server.URL+"/convert/batch", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("リクエストに失敗しました: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("期待値: %d, 実際の値: %d", http.StatusRequestEntityTooLarge, resp.StatusCode)
+	}
+}
+
+func TestHealthAndVersion(t *testing.T) {
+	server := newTestServer(Config{Version: "1.2.3"})
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		expected map[string]string
+	}{
+		{path: "/healthz", expected: map[string]string{"status": "ok"}},
+		{path: "/version", expected: map[string]string{"version": "1.2.3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatalf("リクエストに失敗しました: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var result map[string]string
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("レスポンスのデコードに失敗しました: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("期待値: %v, 実際の値: %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRequestLog(t *testing.T) {
+	var logs bytes.Buffer
+	server := newTestServer(Config{Logger: slog.New(slog.NewJSONHandler(&logs, nil))})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/convert")
+	if err != nil {
+		t.Fatalf("リクエストに失敗しました: %v", err)
+	}
+	resp.Body.Close()
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("ログのデコードに失敗しました: %v (%s)", err, logs.String())
+	}
+	if entry["method"] != "GET" || entry["path"] != "/convert" || entry["status"] != float64(http.StatusMethodNotAllowed) {
+		t.Errorf("予期しないログ: %v", entry)
+	}
+}